@@ -0,0 +1,457 @@
+package optional
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// AnyOption is the sibling of Option for types which are not comparable, such as []string, map[string]int, or structs
+// with slice fields. It has the same API as Option, except that Match is replaced by MatchFunc since the values can not
+// be compared with ==. Since it has IsSome, it works with OrAny, AndAny, Merge, BindFlags, and LoadEnv the same as any
+// other optional.
+//
+// As text, which is used by Set and so by flags and env vars, slices are written as comma separated lists and maps as
+// comma separated key=value pairs, e.g. "10.0.0.0/8,192.168.0.0/16" for an AnyOption[[]netip.Prefix]. Elements which
+// are empty, contain a comma, equals sign or double quote, have leading or trailing whitespace, or are one of the None
+// strings are written as go quoted strings, e.g. "a,b",c for ["a,b", "c"], so that every value reads back the same.
+// Elements are parsed with their UnmarshalText method if they have one, otherwise by kind. Set appends to a slice or map which
+// already holds a value, so repeated flags such as -tag a -tag b,c build up ["a", "b", "c"], while UnmarshalText always
+// replaces the value.
+//
+// Values are copied the same way as any other go value, so an AnyOption of a slice or map shares its backing storage
+// with any copies of it.
+type AnyOption[T any] struct {
+	inner T
+	some  bool
+}
+
+// SomeAny returns an AnyOption holding value.
+func SomeAny[T any](value T) AnyOption[T] {
+	return AnyOption[T]{inner: value, some: true}
+}
+
+// NoneAny returns an AnyOption with no value loaded. As with None, the type must be given like
+// optional.NoneAny[[]string]().
+func NoneAny[T any]() AnyOption[T] {
+	return AnyOption[T]{}
+}
+
+func (o AnyOption[T]) IsSome() bool {
+	return o.some
+}
+
+func (o AnyOption[T]) IsNone() bool {
+	return !o.some
+}
+
+// IsZero returns true for None values so that fields tagged with `json:",omitzero"` are dropped. Unlike Option, an
+// empty slice or map is still Some and is not considered zero.
+func (o AnyOption[T]) IsZero() bool {
+	return !o.some
+}
+
+// Get returns the wrapped value and true if the AnyOption is Some. Note that the value returned if ok == false is
+// undefined so ALWAYS CHECK.
+func (o AnyOption[T]) Get() (val T, ok bool) {
+	return o.inner, o.some
+}
+
+func (o AnyOption[T]) storedValue() (any, bool) {
+	return o.inner, o.some
+}
+
+// MustGet is exactly like Get, but panics for None.
+func (o AnyOption[T]) MustGet() T {
+	if !o.some {
+		panic("Attempted to call MustGet on an AnyOption with None value")
+	}
+	return o.inner
+}
+
+// MatchFunc tests if the AnyOption is Some and eq reports its value as equal to probe, e.g. with slices.Equal.
+func (o AnyOption[T]) MatchFunc(probe T, eq func(a, b T) bool) bool {
+	return o.some && eq(o.inner, probe)
+}
+
+// All returns an iterator which yields the wrapped value once for Some values and nothing for None.
+func (o AnyOption[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if o.some {
+			yield(o.inner)
+		}
+	}
+}
+
+// Clear sets the AnyOption to None.
+func (o *AnyOption[T]) Clear() {
+	var tmp T
+	o.inner = tmp
+	o.some = false
+}
+
+// Default sets the AnyOption to Some(value) if it is None, and reports whether it did.
+func (o *AnyOption[T]) Default(value T) (replaced bool) {
+	if o.some {
+		return false
+	}
+	o.inner = value
+	o.some = true
+	return true
+}
+
+// Replace sets the AnyOption to Some(value) and returns the previous value.
+func (o *AnyOption[T]) Replace(value T) AnyOption[T] {
+	prev := *o
+	o.inner = value
+	o.some = true
+	return prev
+}
+
+// Transform applies f to the wrapped value of a Some AnyOption. None stays None.
+func (o *AnyOption[T]) Transform(f func(T) (T, error)) error {
+	if !o.some {
+		return nil
+	}
+	tmp, err := f(o.inner)
+	if err != nil {
+		return err
+	}
+	o.inner = tmp
+	return nil
+}
+
+func (o AnyOption[T]) Type() string {
+	var tmp T
+	return reflect.TypeOf(&tmp).Elem().String()
+}
+
+func (o AnyOption[T]) String() string {
+	if !o.some {
+		return "None[" + o.Type() + "]"
+	}
+	text, err := o.MarshalText()
+	if err != nil {
+		return fmt.Sprint(o.inner)
+	}
+	return string(text)
+}
+
+// Set parses str the same as UnmarshalText, except that for slices and maps the result is added to any existing value.
+// This lets repeated flags accumulate. BindFlags replaces the value on the first use of a flag, so a default held by the
+// field is overridden rather than added to, and LoadEnv always replaces it.
+func (o *AnyOption[T]) Set(str string) error {
+	if !o.some || isNoneString(str) {
+		return o.UnmarshalText([]byte(str))
+	}
+
+	var parsed T
+	if err := unmarshalAnyText(reflect.ValueOf(&parsed).Elem(), str); err != nil {
+		return err
+	}
+
+	cur := reflect.ValueOf(o.inner)
+	switch cur.Kind() {
+	case reflect.Slice:
+		merged := reflect.AppendSlice(reflect.MakeSlice(cur.Type(), 0, cur.Len()), cur)
+		o.inner = reflect.AppendSlice(merged, reflect.ValueOf(parsed)).Interface().(T)
+	case reflect.Map:
+		merged := reflect.MakeMapWithSize(cur.Type(), cur.Len())
+		for _, m := range []reflect.Value{cur, reflect.ValueOf(parsed)} {
+			entries := m.MapRange()
+			for entries.Next() {
+				merged.SetMapIndex(entries.Key(), entries.Value())
+			}
+		}
+		o.inner = merged.Interface().(T)
+	default:
+		o.inner = parsed
+	}
+	return nil
+}
+
+// accumulator is implemented by pointers to AnyOption, and so to Slice and StrMap, whose Set adds to the current value.
+// Loaders use UnmarshalText instead wherever a new value must replace a default.
+type accumulator interface {
+	UnmarshalText(text []byte) error
+	accumulates()
+}
+
+func (o *AnyOption[T]) accumulates() {}
+
+func (o AnyOption[T]) MarshalText() (text []byte, err error) {
+	if !o.some {
+		return []byte("None"), nil
+	}
+	str, err := marshalAnyText(reflect.ValueOf(&o.inner).Elem())
+	return []byte(str), err
+}
+
+func (o *AnyOption[T]) UnmarshalText(text []byte) error {
+	tmp := string(text)
+	if isNoneString(tmp) {
+		o.Clear()
+		return nil
+	}
+
+	var val T
+	if err := unmarshalAnyText(reflect.ValueOf(&val).Elem(), tmp); err != nil {
+		return err
+	}
+	o.Replace(val)
+	return nil
+}
+
+// MarshalJSON writes None as null and passes Some values to json.Marshal, so slices are written as arrays and maps as
+// objects.
+func (o AnyOption[T]) MarshalJSON() ([]byte, error) {
+	if !o.some {
+		return json.Marshal(nil)
+	}
+	return json.Marshal(o.inner)
+}
+
+// UnmarshalJSON implements encoding/json.Unmarshaller interface
+func (o *AnyOption[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		o.Clear()
+		return nil
+	}
+
+	var val T
+	if err := json.Unmarshal(data, &val); err != nil {
+		return err
+	}
+	o.Replace(val)
+	return nil
+}
+
+// unmarshalAnyText parses text into v, which must be settable. Slices are split on commas and maps on commas and then
+// equals signs, with surrounding whitespace trimmed from each piece. Pieces written as go quoted strings are unquoted,
+// and separators within them are ignored.
+func unmarshalAnyText(v reflect.Value, text string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(text))
+	}
+	if v.Type() == durationType {
+		d, err := parseDuration(text)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(text, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(text, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if err := unmarshalAnyText(elem.Elem(), text); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(text))
+			return nil
+		}
+		list := reflect.MakeSlice(v.Type(), 0, strings.Count(text, ",")+1)
+		if strings.TrimSpace(text) != "" {
+			for _, piece := range splitAnyText(text, ',') {
+				piece, err := unquoteAnyText(piece)
+				if err != nil {
+					return err
+				}
+				elem := reflect.New(v.Type().Elem()).Elem()
+				if err := unmarshalAnyText(elem, piece); err != nil {
+					return err
+				}
+				list = reflect.Append(list, elem)
+			}
+		}
+		v.Set(list)
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		if strings.TrimSpace(text) != "" {
+			for _, piece := range splitAnyText(text, ',') {
+				kv := splitAnyText(piece, '=')
+				if len(kv) < 2 {
+					return fmt.Errorf("map entry %q is not of the form key=value", piece)
+				}
+				k, err := unquoteAnyText(kv[0])
+				if err != nil {
+					return err
+				}
+				val, err := unquoteAnyText(strings.Join(kv[1:], "="))
+				if err != nil {
+					return err
+				}
+				key := reflect.New(v.Type().Key()).Elem()
+				if err := unmarshalAnyText(key, k); err != nil {
+					return err
+				}
+				elem := reflect.New(v.Type().Elem()).Elem()
+				if err := unmarshalAnyText(elem, val); err != nil {
+					return err
+				}
+				m.SetMapIndex(key, elem)
+			}
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("cannot parse text into %s", v.Type())
+	}
+	return nil
+}
+
+// marshalAnyText is the inverse of unmarshalAnyText. Map entries are sorted so the output is stable.
+func marshalAnyText(v reflect.Value) (string, error) {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+	if v.CanAddr() {
+		if m, ok := v.Addr().Interface().(encoding.TextMarshaler); ok {
+			text, err := m.MarshalText()
+			return string(text), err
+		}
+	}
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	case reflect.Pointer:
+		if v.IsNil() {
+			return "", nil
+		}
+		return marshalAnyText(v.Elem())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), nil
+		}
+		pieces := make([]string, v.Len())
+		for i := range pieces {
+			piece, err := marshalAnyText(v.Index(i))
+			if err != nil {
+				return "", err
+			}
+			pieces[i] = quoteAnyText(piece)
+		}
+		return strings.Join(pieces, ","), nil
+	case reflect.Map:
+		var pieces []string
+		entries := v.MapRange()
+		for entries.Next() {
+			k, err := marshalAnyText(entries.Key())
+			if err != nil {
+				return "", err
+			}
+			val, err := marshalAnyText(entries.Value())
+			if err != nil {
+				return "", err
+			}
+			pieces = append(pieces, quoteAnyText(k)+"="+quoteAnyText(val))
+		}
+		slices.Sort(pieces)
+		return strings.Join(pieces, ","), nil
+	default:
+		return "", fmt.Errorf("cannot marshal %s as text", v.Type())
+	}
+}
+
+// quoteAnyText quotes a slice element, map key, or map value if it could not otherwise be read back unchanged by
+// unmarshalAnyText.
+func quoteAnyText(piece string) string {
+	if piece == "" || isNoneString(piece) || strings.TrimSpace(piece) != piece || strings.ContainsAny(piece, `,="`) {
+		return strconv.Quote(piece)
+	}
+	return piece
+}
+
+// unquoteAnyText trims whitespace from piece and unquotes it if it was written as a go quoted string.
+func unquoteAnyText(piece string) (string, error) {
+	piece = strings.TrimSpace(piece)
+	if !strings.HasPrefix(piece, `"`) {
+		return piece, nil
+	}
+	unquoted, err := strconv.Unquote(piece)
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted text %s: %w", piece, err)
+	}
+	return unquoted, nil
+}
+
+// splitAnyText splits text on sep, skipping over quoted pieces. A double quote only starts a quoted piece if it comes
+// first, ignoring whitespace, at the start of the text or after a comma or equals sign, so quotes in the middle of an
+// unquoted piece are kept as they are.
+func splitAnyText(text string, sep byte) []string {
+	var pieces []string
+	start, atStart, quoted, escaped := 0, true, false, false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case quoted:
+			quoted = c != '"'
+		case c == '"' && atStart:
+			quoted = true
+			atStart = false
+		case c == sep:
+			pieces = append(pieces, text[start:i])
+			start = i + 1
+			atStart = true
+		case c == ',' || c == '=':
+			atStart = true
+		case c != ' ' && c != '\t':
+			atStart = false
+		}
+	}
+	return append(pieces, text[start:])
+}
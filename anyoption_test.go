@@ -0,0 +1,234 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"flag"
+	"net/netip"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/brnsampson/optional"
+	"go-simpler.org/env"
+	"gotest.tools/v3/assert"
+)
+
+func TestAnyOptionBasics(t *testing.T) {
+	o := optional.SomeAny([]string{"a", "b"})
+	assert.Assert(t, o.IsSome())
+	assert.DeepEqual(t, []string{"a", "b"}, o.MustGet())
+	assert.Assert(t, o.MatchFunc([]string{"a", "b"}, slices.Equal))
+	assert.Assert(t, !o.MatchFunc([]string{"a"}, slices.Equal))
+
+	none := optional.NoneAny[[]string]()
+	assert.Assert(t, none.IsNone())
+	assert.Assert(t, none.IsZero())
+	assert.Assert(t, !none.MatchFunc(nil, slices.Equal))
+	assert.Equal(t, "None[[]string]", none.String())
+
+	// An empty slice is still Some
+	empty := optional.SomeAny([]string{})
+	assert.Assert(t, empty.IsSome())
+	assert.Assert(t, !empty.IsZero())
+
+	assert.Assert(t, none.Default([]string{"c"}))
+	assert.Assert(t, !none.Default([]string{"d"}))
+	prev := none.Replace([]string{"e"})
+	assert.DeepEqual(t, []string{"c"}, prev.MustGet())
+
+	assert.NilError(t, none.Transform(func(s []string) ([]string, error) { return append(s, "f"), nil }))
+	assert.DeepEqual(t, []string{"e", "f"}, none.MustGet())
+
+	for val := range none.All() {
+		assert.Equal(t, 2, len(val))
+	}
+
+	none.Clear()
+	assert.Assert(t, none.IsNone())
+}
+
+func TestAnyOptionText(t *testing.T) {
+	var tags optional.AnyOption[[]string]
+	assert.NilError(t, tags.UnmarshalText([]byte("a, b,c")))
+	assert.DeepEqual(t, []string{"a", "b", "c"}, tags.MustGet())
+	text, err := tags.MarshalText()
+	assert.NilError(t, err)
+	assert.Equal(t, "a,b,c", string(text))
+	assert.Equal(t, "a,b,c", tags.String())
+
+	// UnmarshalText replaces the value
+	assert.NilError(t, tags.UnmarshalText([]byte("d")))
+	assert.DeepEqual(t, []string{"d"}, tags.MustGet())
+
+	assert.NilError(t, tags.UnmarshalText([]byte("none")))
+	assert.Assert(t, tags.IsNone())
+
+	var limits optional.AnyOption[map[string]int]
+	assert.NilError(t, limits.UnmarshalText([]byte("b=2,a=1")))
+	assert.DeepEqual(t, map[string]int{"a": 1, "b": 2}, limits.MustGet())
+	text, err = limits.MarshalText()
+	assert.NilError(t, err)
+	assert.Equal(t, "a=1,b=2", string(text))
+	assert.ErrorContains(t, limits.UnmarshalText([]byte("a")), "key=value")
+	assert.Assert(t, limits.UnmarshalText([]byte("a=x")) != nil)
+
+	// Elements with an UnmarshalText method use it
+	var cidrs optional.AnyOption[[]netip.Prefix]
+	assert.NilError(t, cidrs.UnmarshalText([]byte("10.0.0.0/8,192.168.0.0/16")))
+	assert.Equal(t, netip.MustParsePrefix("192.168.0.0/16"), cidrs.MustGet()[1])
+	assert.Assert(t, cidrs.UnmarshalText([]byte("10.0.0.0/8,not a cidr")) != nil)
+
+	var durations optional.AnyOption[[]time.Duration]
+	assert.NilError(t, durations.UnmarshalText([]byte("1s,2d")))
+	assert.DeepEqual(t, []time.Duration{time.Second, 48 * time.Hour}, durations.MustGet())
+	text, err = durations.MarshalText()
+	assert.NilError(t, err)
+	assert.Equal(t, "1s,48h0m0s", string(text))
+
+	var empty optional.AnyOption[[]int]
+	assert.NilError(t, empty.UnmarshalText([]byte("")))
+	assert.Assert(t, empty.IsSome())
+	assert.Equal(t, 0, len(empty.MustGet()))
+
+	type unsupported struct{ A []int }
+	var s optional.AnyOption[unsupported]
+	assert.Assert(t, s.UnmarshalText([]byte("x")) != nil)
+}
+
+func TestAnyOptionTextQuoting(t *testing.T) {
+	cases := []struct {
+		opt  optional.AnyOption[[]string]
+		text string
+	}{
+		{optional.SomeAny([]string{"a,b", "c"}), `"a,b",c`},
+		{optional.SomeAny([]string{""}), `""`},
+		{optional.SomeAny([]string{}), ``},
+		{optional.SomeAny([]string{" a", `say "hi"`, `back\slash`}), `" a","say \"hi\"",back\slash`},
+		{optional.SomeAny([]string{"None"}), `"None"`},
+	}
+	for _, c := range cases {
+		text, err := c.opt.MarshalText()
+		assert.NilError(t, err)
+		assert.Equal(t, c.text, string(text))
+
+		var back optional.AnyOption[[]string]
+		assert.NilError(t, back.UnmarshalText(text), c.text)
+		assert.DeepEqual(t, c.opt.MustGet(), back.MustGet())
+	}
+
+	m := optional.SomeAny(map[string]string{"a": "x=y,z", "b=c": "", "d": "e"})
+	text, err := m.MarshalText()
+	assert.NilError(t, err)
+	assert.Equal(t, `"b=c"="",a="x=y,z",d=e`, string(text))
+	var back optional.AnyOption[map[string]string]
+	assert.NilError(t, back.UnmarshalText(text))
+	assert.DeepEqual(t, m.MustGet(), back.MustGet())
+
+	// Quotes within an unquoted piece are kept, while a bad quoted piece is an error
+	var tags optional.AnyOption[[]string]
+	assert.NilError(t, tags.UnmarshalText([]byte(`a"b, c`)))
+	assert.DeepEqual(t, []string{`a"b`, "c"}, tags.MustGet())
+	assert.Assert(t, tags.UnmarshalText([]byte(`"a,b`)) != nil)
+}
+
+func TestAnyOptionJSON(t *testing.T) {
+	type config struct {
+		Tags   optional.AnyOption[[]string]       `json:"tags"`
+		Limits optional.AnyOption[map[string]int] `json:"limits"`
+		CIDRs  optional.AnyOption[[]netip.Prefix] `json:"cidrs"`
+	}
+
+	var c config
+	assert.NilError(t, json.Unmarshal([]byte(`{"tags":["a","b"],"limits":null,"cidrs":["10.0.0.0/8"]}`), &c))
+	assert.DeepEqual(t, []string{"a", "b"}, c.Tags.MustGet())
+	assert.Assert(t, c.Limits.IsNone())
+	assert.Equal(t, netip.MustParsePrefix("10.0.0.0/8"), c.CIDRs.MustGet()[0])
+
+	res, err := json.Marshal(c)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"tags":["a","b"],"limits":null,"cidrs":["10.0.0.0/8"]}`, string(res))
+
+	assert.Assert(t, json.Unmarshal([]byte(`{"tags":"a"}`), &c) != nil)
+}
+
+func TestAnyOptionFlags(t *testing.T) {
+	type config struct {
+		Tags   optional.AnyOption[[]string]       `flag:"tag" usage:"tags to apply"`
+		Limits optional.AnyOption[map[string]int] `flag:"limit"`
+		Port   optional.Int                       `flag:"port"`
+	}
+
+	var c config
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	assert.NilError(t, optional.BindFlags(fs, &c))
+	assert.NilError(t, fs.Parse([]string{"-tag", "a", "-tag", "b,c", "-limit", "x=1", "-limit", "y=2,x=3"}))
+
+	// Repeated flags accumulate
+	assert.DeepEqual(t, []string{"a", "b", "c"}, c.Tags.MustGet())
+	assert.DeepEqual(t, map[string]int{"x": 3, "y": 2}, c.Limits.MustGet())
+	assert.Assert(t, c.Port.IsNone())
+
+	// A default held by the field is replaced by the first use of the flag, and later uses add to it
+	defaults := config{Tags: optional.SomeAny([]string{"default"}), Limits: optional.SomeAny(map[string]int{"z": 9})}
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	assert.NilError(t, optional.BindFlags(fs, &defaults))
+	assert.NilError(t, fs.Parse([]string{"-tag", "a", "-tag", "b", "-limit", "x=1"}))
+	assert.DeepEqual(t, []string{"a", "b"}, defaults.Tags.MustGet())
+	assert.DeepEqual(t, map[string]int{"x": 1}, defaults.Limits.MustGet())
+
+	// and is kept if the flag is not used
+	defaults = config{Tags: optional.SomeAny([]string{"default"})}
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	assert.NilError(t, optional.BindFlags(fs, &defaults))
+	assert.NilError(t, fs.Parse(nil))
+	assert.DeepEqual(t, []string{"default"}, defaults.Tags.MustGet())
+	assert.Equal(t, "default", fs.Lookup("tag").Value.String())
+
+	var unset config
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	assert.NilError(t, optional.BindFlags(fs, &unset))
+	assert.NilError(t, fs.Parse(nil))
+	assert.Assert(t, unset.Tags.IsNone())
+}
+
+func TestAnyOptionEnv(t *testing.T) {
+	type config struct {
+		Tags optional.AnyOption[[]string] `env:"TAGS"`
+	}
+
+	var c config
+	assert.NilError(t, optional.LoadEnv(&c, optional.WithEnvSource(env.Map{"TAGS": "a,b"})))
+	assert.DeepEqual(t, []string{"a", "b"}, c.Tags.MustGet())
+
+	// Variables replace a default rather than adding to it
+	c = config{Tags: optional.SomeAny([]string{"default"})}
+	assert.NilError(t, optional.LoadEnv(&c, optional.WithEnvSource(env.Map{"TAGS": "a"})))
+	assert.DeepEqual(t, []string{"a"}, c.Tags.MustGet())
+}
+
+func TestAnyOptionOrAndMerge(t *testing.T) {
+	some := optional.SomeAny([]string{"a"})
+	none := optional.NoneAny[[]string]()
+
+	assert.DeepEqual(t, []string{"a"}, optional.OrAny(none, some).MustGet())
+	assert.DeepEqual(t, []string{"a"}, optional.OrAny(some, none).MustGet())
+	assert.Assert(t, optional.AndAny(none, some).IsNone())
+	assert.Assert(t, optional.AndAny(some, none).IsNone())
+	assert.DeepEqual(t, []string{"b"}, optional.OrAny(optional.NoStrs(), optional.SomeStrs("b")).MustGet())
+
+	// The existing optionals work with OrAny and AndAny as well as Or and And
+	assert.Equal(t, 1, optional.OrAny(optional.NoInt(), optional.SomeInt(1)).MustGet())
+	assert.Assert(t, optional.AndAny(optional.NoStr(), optional.SomeStr("a")).IsNone())
+	assert.Equal(t, 1, optional.Or(optional.NoInt(), optional.SomeInt(1)).MustGet())
+	assert.Assert(t, optional.And(optional.NoStr(), optional.SomeStr("a")).IsNone())
+
+	type config struct {
+		Tags optional.AnyOption[[]string]
+		Port optional.Int
+	}
+	dst := config{Port: optional.SomeInt(80)}
+	src := config{Tags: optional.SomeAny([]string{"x"}), Port: optional.SomeInt(8080)}
+	assert.NilError(t, optional.Merge(&dst, src))
+	assert.DeepEqual(t, []string{"x"}, dst.Tags.MustGet())
+	assert.Equal(t, 80, dst.Port.MustGet())
+}
@@ -2,6 +2,7 @@ package optional
 
 import (
 	"database/sql/driver"
+	"flag"
 	"fmt"
 	"strconv"
 )
@@ -26,6 +27,24 @@ func (o *Bool) Set(str string) error {
 	return o.UnmarshalText([]byte(str))
 }
 
+// IsBoolFlag marks Bool as a boolean flag for the flag package, so passing a bare -debug on the command line is the
+// same as -debug=true. Flags which are not passed at all leave the Bool as None.
+func (o Bool) IsBoolFlag() bool {
+	return true
+}
+
+// Negated returns a flag.Value which sets the Bool to the opposite of whatever it is given. This allows registering a
+// companion flag like -no-debug which sets the Bool to Some(false):
+//
+// var debug optional.Bool
+// flag.Var(&debug, "debug", "enable debug logging")
+// flag.Var(debug.Negated(), "no-debug", "disable debug logging")
+//
+// BindFlags does this automatically for Bool fields tagged with the negatable option, e.g. `flag:"debug,negatable"`.
+func (o *Bool) Negated() flag.Value {
+	return negatedBool{o}
+}
+
 func (o Bool) String() string {
 	if o.IsNone() {
 		return "None[Bool]"
@@ -65,25 +84,19 @@ func (o *Bool) UnmarshalText(text []byte) error {
 	return nil
 }
 
-// Scan implements database/sql.Scanner interface.
+// Scan implements database/sql.Scanner interface. Numbers are accepted as long as they are exactly 1 or 0, and strings
+// are parsed with strconv.ParseBool.
 func (o *Bool) Scan(src any) error {
 	if src == nil {
 		// NULL value row
 		o.Clear()
 		return nil
 	}
-	switch t := src.(type) {
-	case bool:
-		_ = o.Replace(t)
-	case string:
-		tmp, err := strconv.ParseBool(t)
-		if err != nil {
-			return err
-		}
-		_ = o.Replace(tmp)
-	default:
-		return fmt.Errorf("converting driver.Value type %T to %s", src, o.Type())
+	val, err := scanBool(src)
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
 	}
+	_ = o.Replace(val)
 	return nil
 }
 
@@ -101,3 +114,28 @@ func (o Bool) Value() (driver.Value, error) {
 func (o *Bool) True() bool {
 	return o.Match(true)
 }
+
+// negatedBool is the flag.Value returned by Bool.Negated.
+type negatedBool struct {
+	b *Bool
+}
+
+func (n negatedBool) IsBoolFlag() bool {
+	return true
+}
+
+func (n negatedBool) Set(str string) error {
+	tmp, err := strconv.ParseBool(str)
+	if err != nil {
+		return err
+	}
+	n.b.Replace(!tmp)
+	return nil
+}
+
+func (n negatedBool) String() string {
+	if n.b == nil || n.b.IsNone() {
+		return "None[Bool]"
+	}
+	return strconv.FormatBool(!n.b.MustGet())
+}
@@ -1,6 +1,7 @@
 package optional_test
 
 import (
+	"flag"
 	"reflect"
 	"testing"
 
@@ -135,3 +136,27 @@ func TestBoolSql(t *testing.T) {
 		assert.Equal(t, test3.IsNone(), out3.IsNone(), "None / NULL case failed")
 	}
 }
+
+func TestBoolFlag(t *testing.T) {
+	var debug optional.Bool
+	var verbose optional.Bool
+	var color optional.Bool
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&debug, "debug", "")
+	fs.Var(debug.Negated(), "no-debug", "")
+	fs.Var(&verbose, "verbose", "")
+	fs.Var(&color, "color", "")
+	fs.Var(color.Negated(), "no-color", "")
+
+	err := fs.Parse([]string{"-debug", "-no-color"})
+	assert.NilError(t, err)
+	assert.Assert(t, debug.Match(true))
+	assert.Assert(t, color.Match(false))
+	assert.Assert(t, verbose.IsNone())
+
+	// The last flag given wins
+	err = fs.Parse([]string{"-debug", "-no-debug", "-verbose=false"})
+	assert.NilError(t, err)
+	assert.Assert(t, debug.Match(false))
+	assert.Assert(t, verbose.Match(false))
+}
@@ -81,7 +81,31 @@ func (o *Byte) UnmarshalText(text []byte) error {
 	return nil
 }
 
-// Scan implements database/sql.Scanner interface.
+// MarshalTOML implements the toml.Marshaler interface. Bytes are written as hex strings, the same as MarshalText.
+func (o Byte) MarshalTOML() ([]byte, error) {
+	tmp, err := o.MarshalText()
+	return []byte(tomlQuote(string(tmp))), err
+}
+
+// UnmarshalTOML implements the toml.Unmarshaler interface. Strings are parsed the same as UnmarshalText and integers
+// must fit into a byte.
+func (o *Byte) UnmarshalTOML(data any) error {
+	switch t := data.(type) {
+	case int64:
+		if t > math.MaxUint8 || t < 0 {
+			return fmt.Errorf("%d outside of range of a byte", t)
+		}
+		_ = o.Replace(byte(t))
+	case string:
+		return o.UnmarshalText([]byte(t))
+	default:
+		return fmt.Errorf("converting TOML type %T to %s", data, o.Type())
+	}
+	return nil
+}
+
+// Scan implements database/sql.Scanner interface. Strings and []byte are parsed the same as UnmarshalText, while
+// numbers and bools must fit into a byte.
 func (o *Byte) Scan(src any) error {
 	if src == nil {
 		// NULL value row
@@ -97,15 +121,12 @@ func (o *Byte) Scan(src any) error {
 		if err := o.UnmarshalText(t); err != nil {
 			return err
 		}
-	case int64:
-		if t > math.MaxUint8 || t < 0 {
-			return fmt.Errorf("%d outside of range of a byte", t)
-		}
-		_ = o.Replace(byte(t))
-	case uint8:
-		_ = o.Replace(byte(t))
 	default:
-		return fmt.Errorf("converting driver.Value type %T to %s", src, o.Type())
+		val, err := scanNumber[byte](src)
+		if err != nil {
+			return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
+		}
+		_ = o.Replace(val)
 	}
 	return nil
 }
@@ -118,3 +139,9 @@ func (o Byte) Value() (driver.Value, error) {
 	}
 	return nil, nil
 }
+
+// Cmp compares o with other the same as Compare, with None sorting first. It can be passed straight to slices.SortFunc
+// as Byte.Cmp.
+func (o Byte) Cmp(other Byte) int {
+	return Compare(o.Option, other.Option, true)
+}
@@ -0,0 +1,222 @@
+package optional
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// envelopePrefix marks a string as an encrypted secret envelope. The full format is
+// enc:v1:<key id>:<base64 encoded ciphertext>
+const envelopePrefix = "enc:v1:"
+
+// SecretCipher encrypts and decrypts Secrets at rest. Implementations are responsible for choosing which key to
+// encrypt with and reporting its ID so that the matching key can be found again when decrypting, which is what allows
+// keys to be rotated without re-encrypting everything at once.
+type SecretCipher interface {
+	// Encrypt returns the ciphertext for plaintext along with the ID of the key used to encrypt it.
+	Encrypt(plaintext []byte) (keyID string, ciphertext []byte, err error)
+	// Decrypt returns the plaintext for ciphertext which was encrypted with the key identified by keyID.
+	Decrypt(keyID string, ciphertext []byte) ([]byte, error)
+}
+
+var (
+	defaultCipherMu    sync.RWMutex
+	defaultCipher      SecretCipher
+	envelopeMarshaling bool
+)
+
+// SetDefaultSecretCipher sets the process wide SecretCipher used by every Secret which was not given its own with
+// Secret.WithCipher. Passing nil disables encryption for those Secrets, which is the default.
+func SetDefaultSecretCipher(c SecretCipher) {
+	defaultCipherMu.Lock()
+	defer defaultCipherMu.Unlock()
+	defaultCipher = c
+}
+
+// DefaultSecretCipher returns the SecretCipher set with SetDefaultSecretCipher, or nil if there isn't one.
+func DefaultSecretCipher() SecretCipher {
+	defaultCipherMu.RLock()
+	defer defaultCipherMu.RUnlock()
+	return defaultCipher
+}
+
+// SetSecretEnvelopeMarshaling opts in to encrypting Secrets in MarshalText and MarshalJSON as well as in Value. This is
+// meant for writing config files which should not hold plaintext secrets. Secrets without a cipher are still marshaled
+// as plaintext. Unmarshaling always recognizes envelopes, whether or not this is enabled.
+func SetSecretEnvelopeMarshaling(enabled bool) {
+	defaultCipherMu.Lock()
+	defer defaultCipherMu.Unlock()
+	envelopeMarshaling = enabled
+}
+
+func secretEnvelopeMarshaling() bool {
+	defaultCipherMu.RLock()
+	defer defaultCipherMu.RUnlock()
+	return envelopeMarshaling
+}
+
+// isEnvelope reports if s looks like an encrypted secret envelope. Anything which does is always treated as one, and
+// fails to unmarshal if it can't be decrypted, rather than falling back to treating it as plaintext.
+func isEnvelope(s string) bool {
+	return strings.HasPrefix(s, envelopePrefix)
+}
+
+// checkPlaintext fails closed for plaintext secrets which begin with the envelope prefix. They would be read back as
+// an envelope, so rather than writing something which can't be unmarshaled again, the secret must be encrypted.
+func checkPlaintext(plaintext []byte) error {
+	if bytes.HasPrefix(plaintext, []byte(envelopePrefix)) {
+		return fmt.Errorf("plaintext secret begins with %q and would be read back as an encrypted envelope", envelopePrefix)
+	}
+	return nil
+}
+
+// sealEnvelope encrypts plaintext with c and wraps the result in an envelope which records the key ID.
+func sealEnvelope(c SecretCipher, plaintext []byte) (string, error) {
+	keyID, ciphertext, err := c.Encrypt(plaintext)
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(keyID, ":") {
+		return "", fmt.Errorf("secret cipher key ID %q must not contain ':'", keyID)
+	}
+	return envelopePrefix + keyID + ":" + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// openEnvelope decrypts an envelope created by sealEnvelope.
+func openEnvelope(c SecretCipher, envelope string) ([]byte, error) {
+	if c == nil {
+		return nil, optionalError("found an encrypted secret, but no SecretCipher is configured to decrypt it")
+	}
+
+	keyID, encoded, ok := strings.Cut(strings.TrimPrefix(envelope, envelopePrefix), ":")
+	if !ok {
+		return nil, optionalError("malformed encrypted secret envelope")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("malformed encrypted secret envelope: %w", err)
+	}
+	return c.Decrypt(keyID, ciphertext)
+}
+
+// Keyring is the default SecretCipher. It holds any number of AES-GCM keys, each with a unique ID. New secrets are
+// always encrypted with the primary key, while any key in the ring may be used to decrypt. To rotate keys, add a new key
+// with Rotate, re-encrypt anything stored under the old key at your leisure, then remove the old key with RemoveKey.
+//
+// The zero value is an empty Keyring. Encrypt returns an error until a primary key is set with Rotate.
+type Keyring struct {
+	mu      sync.RWMutex
+	keys    map[string]cipher.AEAD
+	primary string
+}
+
+// NewKeyring creates a Keyring with a single primary key. The key must be 16, 24, or 32 bytes long to select AES-128,
+// AES-192, or AES-256.
+func NewKeyring(keyID string, key []byte) (*Keyring, error) {
+	k := &Keyring{}
+	if err := k.Rotate(keyID, key); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// AddKey adds a key which can be used for decryption without changing the primary key. Key IDs can not be reused, since
+// replacing a key would leave every envelope sealed with the old one undecryptable; remove the old key first.
+func (k *Keyring) AddKey(keyID string, key []byte) error {
+	if keyID == "" || strings.Contains(keyID, ":") {
+		return fmt.Errorf("invalid key ID %q: must be non-empty and not contain ':'", keyID)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.keys == nil {
+		k.keys = make(map[string]cipher.AEAD)
+	}
+	if _, ok := k.keys[keyID]; ok {
+		return fmt.Errorf("key ID %q is already in the keyring", keyID)
+	}
+	k.keys[keyID] = aead
+	return nil
+}
+
+// Rotate adds a key and makes it the primary key, so it is used for all encryption from now on.
+func (k *Keyring) Rotate(keyID string, key []byte) error {
+	if err := k.AddKey(keyID, key); err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.primary = keyID
+	return nil
+}
+
+// RemoveKey removes a retired key from the ring. The primary key can not be removed.
+func (k *Keyring) RemoveKey(keyID string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if keyID == k.primary {
+		return fmt.Errorf("can not remove primary key %q", keyID)
+	}
+	delete(k.keys, keyID)
+	return nil
+}
+
+// PrimaryKeyID returns the ID of the key currently used for encryption.
+func (k *Keyring) PrimaryKeyID() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.primary
+}
+
+// Encrypt implements SecretCipher. A random nonce is generated for every call and prepended to the ciphertext.
+func (k *Keyring) Encrypt(plaintext []byte) (string, []byte, error) {
+	k.mu.RLock()
+	keyID := k.primary
+	aead, ok := k.keys[keyID]
+	k.mu.RUnlock()
+
+	if !ok {
+		return "", nil, optionalError("keyring has no primary key to encrypt with")
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	// The key ID is used as additional data so that a ciphertext can't be passed off as belonging to another key.
+	return keyID, aead.Seal(nonce, nonce, plaintext, []byte(keyID)), nil
+}
+
+// Decrypt implements SecretCipher.
+func (k *Keyring) Decrypt(keyID string, ciphertext []byte) ([]byte, error) {
+	k.mu.RLock()
+	aead, ok := k.keys[keyID]
+	k.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no key with ID %q in keyring", keyID)
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, optionalError("encrypted secret is too short")
+	}
+
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, []byte(keyID))
+}
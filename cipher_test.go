@@ -0,0 +1,226 @@
+package optional_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/brnsampson/optional"
+	"gotest.tools/v3/assert"
+)
+
+var (
+	testKey1 = bytes.Repeat([]byte{1}, 32)
+	testKey2 = bytes.Repeat([]byte{2}, 32)
+)
+
+func TestKeyringRoundTrip(t *testing.T) {
+	kr, err := optional.NewKeyring("k1", testKey1)
+	assert.NilError(t, err)
+
+	keyID, ciphertext, err := kr.Encrypt([]byte("hunter2"))
+	assert.NilError(t, err)
+	assert.Equal(t, "k1", keyID)
+	assert.Assert(t, !bytes.Contains(ciphertext, []byte("hunter2")))
+
+	plaintext, err := kr.Decrypt(keyID, ciphertext)
+	assert.NilError(t, err)
+	assert.Equal(t, "hunter2", string(plaintext))
+
+	// The key ID is authenticated, so ciphertext can't be decrypted under a different ID even with the same key
+	assert.NilError(t, kr.AddKey("other", testKey1))
+	_, err = kr.Decrypt("other", ciphertext)
+	assert.Assert(t, err != nil)
+
+	_, err = kr.Decrypt("missing", ciphertext)
+	assert.ErrorContains(t, err, "missing")
+}
+
+func TestKeyringInvalid(t *testing.T) {
+	_, err := optional.NewKeyring("k1", []byte("short"))
+	assert.Assert(t, err != nil)
+
+	_, err = optional.NewKeyring("bad:id", testKey1)
+	assert.Assert(t, err != nil)
+
+	_, err = optional.NewKeyring("", testKey1)
+	assert.Assert(t, err != nil)
+}
+
+func TestKeyringZeroValue(t *testing.T) {
+	var kr optional.Keyring
+	_, _, err := kr.Encrypt([]byte("hunter2"))
+	assert.ErrorContains(t, err, "no primary key")
+	_, err = kr.Decrypt("k1", []byte("ciphertext"))
+	assert.ErrorContains(t, err, "k1")
+
+	// Secrets using an empty keyring fail rather than panic
+	_, err = optional.SomeSecret("hunter2").WithCipher(&kr).Value()
+	assert.Assert(t, err != nil)
+
+	assert.NilError(t, kr.AddKey("k1", testKey1))
+	_, _, err = kr.Encrypt([]byte("hunter2"))
+	assert.Assert(t, err != nil)
+
+	assert.NilError(t, kr.Rotate("k2", testKey2))
+	keyID, ciphertext, err := kr.Encrypt([]byte("hunter2"))
+	assert.NilError(t, err)
+	plaintext, err := kr.Decrypt(keyID, ciphertext)
+	assert.NilError(t, err)
+	assert.Equal(t, "hunter2", string(plaintext))
+}
+
+func TestSecretPlaintextLooksLikeEnvelope(t *testing.T) {
+	tricky := optional.SomeSecret("enc:v1:k1:aGVsbG8=")
+
+	// Plaintext which would be read back as an envelope is never written out
+	_, err := tricky.MarshalText()
+	assert.ErrorContains(t, err, "enc:v1:")
+	_, err = json.Marshal(tricky)
+	assert.ErrorContains(t, err, "enc:v1:")
+	_, err = tricky.Value()
+	assert.ErrorContains(t, err, "enc:v1:")
+	_, err = optional.SomeSecretBytes([]byte("enc:v1:k1:aGVsbG8=")).Value()
+	assert.ErrorContains(t, err, "enc:v1:")
+
+	// and text which looks like an envelope is never taken as plaintext
+	var s optional.Secret
+	assert.Assert(t, s.Set("enc:v1:k1:aGVsbG8=") != nil)
+	assert.Assert(t, s.IsNone())
+
+	// Encrypting it is fine, since the envelope is unambiguous
+	kr, err := optional.NewKeyring("k1", testKey1)
+	assert.NilError(t, err)
+	val, err := tricky.WithCipher(kr).Value()
+	assert.NilError(t, err)
+	out := optional.NoSecret().WithCipher(kr)
+	assert.NilError(t, out.Scan(val))
+	assert.Assert(t, out.Match("enc:v1:k1:aGVsbG8="))
+}
+
+func TestKeyringRotate(t *testing.T) {
+	kr, err := optional.NewKeyring("k1", testKey1)
+	assert.NilError(t, err)
+
+	s := optional.SomeSecret("rotate me").WithCipher(kr)
+	old, err := s.Value()
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(old.(string), "enc:v1:k1:"))
+
+	// Reusing a key ID is an error, and leaves the old key in place
+	assert.ErrorContains(t, kr.Rotate("k1", testKey2), "already")
+	assert.ErrorContains(t, kr.AddKey("k1", testKey2), "already")
+	assert.Equal(t, "k1", kr.PrimaryKeyID())
+
+	assert.NilError(t, kr.Rotate("k2", testKey2))
+	assert.Equal(t, "k2", kr.PrimaryKeyID())
+
+	updated, err := s.Value()
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(updated.(string), "enc:v1:k2:"))
+
+	// Values written under the old key can still be read until it is removed
+	out := optional.NoSecret().WithCipher(kr)
+	assert.NilError(t, out.Scan(old))
+	assert.Assert(t, out.Match("rotate me"))
+
+	assert.Assert(t, kr.RemoveKey("k2") != nil)
+	assert.NilError(t, kr.RemoveKey("k1"))
+	assert.Assert(t, out.Scan(old) != nil)
+	assert.NilError(t, out.Scan(updated))
+	assert.Assert(t, out.Match("rotate me"))
+}
+
+func TestSecretValueEncrypted(t *testing.T) {
+	kr, err := optional.NewKeyring("k1", testKey1)
+	assert.NilError(t, err)
+
+	// Without a cipher secrets are stored as plaintext
+	plain, err := optional.SomeSecret("hunter2").Value()
+	assert.NilError(t, err)
+	assert.Equal(t, "hunter2", plain)
+
+	s := optional.SomeSecret("hunter2").WithCipher(kr)
+	val, err := s.Value()
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(val.(string), "hunter2"))
+
+	// The same plaintext encrypts differently every time
+	val2, err := s.Value()
+	assert.NilError(t, err)
+	assert.Assert(t, val != val2)
+
+	out := optional.NoSecret().WithCipher(kr)
+	assert.NilError(t, out.Scan([]byte(val.(string))))
+	assert.Assert(t, out.Match("hunter2"))
+
+	// Plaintext rows are still accepted
+	assert.NilError(t, out.Scan("plaintext"))
+	assert.Assert(t, out.Match("plaintext"))
+
+	// Envelopes can't be read without a cipher
+	plainOut := optional.NoSecret()
+	assert.ErrorContains(t, plainOut.Scan(val), "no SecretCipher")
+
+	none, err := optional.NoSecret().WithCipher(kr).Value()
+	assert.NilError(t, err)
+	assert.Assert(t, none == nil)
+}
+
+func TestSecretDefaultCipher(t *testing.T) {
+	kr, err := optional.NewKeyring("default", testKey1)
+	assert.NilError(t, err)
+
+	optional.SetDefaultSecretCipher(kr)
+	defer optional.SetDefaultSecretCipher(nil)
+	assert.Equal(t, optional.SecretCipher(kr), optional.DefaultSecretCipher())
+
+	val, err := optional.SomeSecret("hunter2").Value()
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(val.(string), "enc:v1:default:"))
+
+	var out optional.Secret
+	assert.NilError(t, out.Scan(val))
+	assert.Assert(t, out.Match("hunter2"))
+}
+
+func TestSecretEnvelopeMarshaling(t *testing.T) {
+	kr, err := optional.NewKeyring("k1", testKey1)
+	assert.NilError(t, err)
+
+	type config struct {
+		Password optional.Secret `json:"password"`
+	}
+	cfg := config{Password: optional.SomeSecret("hunter2").WithCipher(kr)}
+
+	// Marshaling writes plaintext until envelopes are enabled
+	data, err := json.Marshal(cfg)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"password":"hunter2"}`, string(data))
+
+	optional.SetSecretEnvelopeMarshaling(true)
+	defer optional.SetSecretEnvelopeMarshaling(false)
+
+	data, err = json.Marshal(cfg)
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(string(data), "hunter2"))
+	assert.Assert(t, strings.Contains(string(data), `"enc:v1:k1:`))
+
+	out := config{Password: optional.NoSecret().WithCipher(kr)}
+	assert.NilError(t, json.Unmarshal(data, &out))
+	assert.Assert(t, out.Password.Match("hunter2"))
+
+	text, err := cfg.Password.MarshalText()
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(string(text), "enc:v1:k1:"))
+
+	flagged := optional.NoSecret().WithCipher(kr)
+	assert.NilError(t, flagged.Set(string(text)))
+	assert.Assert(t, flagged.Match("hunter2"))
+
+	// None is never encrypted
+	none, err := optional.NoSecret().WithCipher(kr).MarshalText()
+	assert.NilError(t, err)
+	assert.Equal(t, "None", string(none))
+}
@@ -0,0 +1,61 @@
+package optional
+
+import "cmp"
+
+// Compare returns -1 if a is less than b, 0 if they are equal, and +1 if a is greater than b. Two None values are
+// equal, and None sorts before every Some value if noneFirst is true, otherwise after. Some values are compared with
+// cmp.Compare, so NaN sorts before every other float.
+//
+// Sort a slice with e.g.
+//
+//	slices.SortFunc(opts, func(a, b optional.Option[int]) int { return optional.Compare(a, b, true) })
+func Compare[T cmp.Ordered](a, b Option[T], noneFirst bool) int {
+	return CompareFunc(a, b, noneFirst, cmp.Compare[T])
+}
+
+// CompareFunc is the same as Compare, but Some values are compared with cmpFn. This allows ordering options which
+// hold types such as time.Time that are not cmp.Ordered.
+func CompareFunc[T comparable](a, b Option[T], noneFirst bool, cmpFn func(x, y T) int) int {
+	x, aOk := a.Get()
+	y, bOk := b.Get()
+	switch {
+	case aOk && bOk:
+		return cmpFn(x, y)
+	case !aOk && !bOk:
+		return 0
+	case !aOk == noneFirst:
+		return -1
+	default:
+		return +1
+	}
+}
+
+// Less reports if a sorts before b, following the same rules as Compare.
+func Less[T cmp.Ordered](a, b Option[T], noneFirst bool) bool {
+	return Compare(a, b, noneFirst) < 0
+}
+
+// Min returns the smallest Some value in opts, ignoring None values. It returns None if opts has no Some values.
+func Min[T cmp.Ordered](opts []Option[T]) Option[T] {
+	return extreme(opts, -1)
+}
+
+// Max returns the largest Some value in opts, ignoring None values. It returns None if opts has no Some values.
+func Max[T cmp.Ordered](opts []Option[T]) Option[T] {
+	return extreme(opts, +1)
+}
+
+// extreme returns the smallest Some value in opts if sign is -1, or the largest if it is +1. Ties go to the earliest
+// value.
+func extreme[T cmp.Ordered](opts []Option[T], sign int) Option[T] {
+	res := None[T]()
+	for _, o := range opts {
+		if o.IsNone() {
+			continue
+		}
+		if res.IsNone() || Compare(o, res, true) == sign {
+			res = o
+		}
+	}
+	return res
+}
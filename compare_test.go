@@ -0,0 +1,112 @@
+package optional_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/brnsampson/optional"
+	"gotest.tools/v3/assert"
+)
+
+func TestCompare(t *testing.T) {
+	one := optional.Some(1)
+	two := optional.Some(2)
+	none := optional.None[int]()
+
+	assert.Equal(t, -1, optional.Compare(one, two, true))
+	assert.Equal(t, +1, optional.Compare(two, one, true))
+	assert.Equal(t, 0, optional.Compare(one, optional.Some(1), true))
+	assert.Equal(t, 0, optional.Compare(none, none, true))
+	assert.Equal(t, 0, optional.Compare(none, none, false))
+
+	assert.Equal(t, -1, optional.Compare(none, one, true))
+	assert.Equal(t, +1, optional.Compare(one, none, true))
+	assert.Equal(t, +1, optional.Compare(none, one, false))
+	assert.Equal(t, -1, optional.Compare(one, none, false))
+
+	assert.Assert(t, optional.Less(none, one, true))
+	assert.Assert(t, !optional.Less(none, one, false))
+	assert.Assert(t, !optional.Less(one, one, true))
+
+	// NaN sorts before other floats, the same as cmp.Compare
+	assert.Equal(t, -1, optional.Compare(optional.Some(math.NaN()), optional.Some(math.Inf(-1)), true))
+}
+
+func TestSortFunc(t *testing.T) {
+	opts := []optional.Option[string]{
+		optional.Some("b"),
+		optional.None[string](),
+		optional.Some("a"),
+		optional.Some("c"),
+	}
+
+	slices.SortFunc(opts, func(a, b optional.Option[string]) int { return optional.Compare(a, b, false) })
+	expected := []optional.Option[string]{
+		optional.Some("a"),
+		optional.Some("b"),
+		optional.Some("c"),
+		optional.None[string](),
+	}
+	for i := range expected {
+		assert.Assert(t, optional.Equal(expected[i], opts[i]), "index %d", i)
+	}
+
+	ints := []optional.Int{optional.SomeInt(3), optional.NoInt(), optional.SomeInt(-1)}
+	slices.SortFunc(ints, optional.Int.Cmp)
+	assert.Assert(t, ints[0].IsNone())
+	assert.Equal(t, -1, ints[1].MustGet())
+	assert.Equal(t, 3, ints[2].MustGet())
+
+	strs := []optional.Str{optional.SomeStr("z"), optional.SomeStr("y"), optional.NoStr()}
+	slices.SortStableFunc(strs, optional.Str.Cmp)
+	assert.Assert(t, strs[0].IsNone())
+	assert.Equal(t, "y", strs[1].MustGet())
+
+	durations := []optional.Duration{optional.SomeDuration(time.Hour), optional.SomeDuration(time.Second)}
+	slices.SortFunc(durations, optional.Duration.Cmp)
+	assert.Equal(t, time.Second, durations[0].MustGet())
+}
+
+func TestMinMax(t *testing.T) {
+	opts := []optional.Option[float64]{
+		optional.None[float64](),
+		optional.Some(2.5),
+		optional.Some(-1.0),
+		optional.None[float64](),
+		optional.Some(7.0),
+	}
+	assert.Equal(t, -1.0, optional.Min(opts).MustGet())
+	assert.Equal(t, 7.0, optional.Max(opts).MustGet())
+
+	nones := []optional.Option[float64]{optional.None[float64](), optional.None[float64]()}
+	assert.Assert(t, optional.Min(nones).IsNone())
+	assert.Assert(t, optional.Max(nones).IsNone())
+	assert.Assert(t, optional.Max[int](nil).IsNone())
+}
+
+func TestTimeCmp(t *testing.T) {
+	utc := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	paris, err := time.LoadLocation("Europe/Paris")
+	assert.NilError(t, err)
+
+	// The same instant in different locations is equal
+	a := optional.SomeTime(utc)
+	b := optional.SomeTime(utc.In(paris))
+	assert.Equal(t, 0, a.Cmp(b))
+
+	later := optional.SomeTime(utc.Add(time.Minute))
+	assert.Equal(t, -1, a.Cmp(later))
+	assert.Equal(t, +1, later.Cmp(a))
+	assert.Equal(t, -1, optional.NoTime().Cmp(a))
+
+	times := []optional.Time{later, optional.NoTime(), a}
+	slices.SortFunc(times, optional.Time.Cmp)
+	assert.Assert(t, times[0].IsNone())
+	assert.Assert(t, times[1].MustGet().Equal(utc))
+	assert.Assert(t, times[2].MustGet().Equal(utc.Add(time.Minute)))
+
+	// CompareFunc gives the same control over None placement for types which are not cmp.Ordered
+	assert.Equal(t, +1, optional.CompareFunc(optional.None[time.Time](), a.Option, false, time.Time.Compare))
+}
@@ -0,0 +1,423 @@
+package optional
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Output formats for FormattedDuration.StringFormat and FormattedDuration.DataFormat. An empty format is the same as
+// DURATION_GO_FORMAT.
+const (
+	// DURATION_GO_FORMAT writes durations with time.Duration.String, e.g. 49h30m0s.
+	DURATION_GO_FORMAT string = "go"
+	// DURATION_DAYS_FORMAT is the same as DURATION_GO_FORMAT, except whole days are split out, e.g. 2d1h30m0s.
+	DURATION_DAYS_FORMAT string = "days"
+	// DURATION_ISO8601_FORMAT writes ISO 8601 durations, e.g. P2DT1H30M.
+	DURATION_ISO8601_FORMAT string = "iso8601"
+)
+
+// FormattedDuration is a Duration which String and MarshalText write in a chosen format, created with
+// Duration.WithFormat or Duration.WithPrecision. It parses the same as Duration, so every format can be read back. It
+// is a separate type so that Duration stays a plain wrapper around Option[time.Duration].
+//
+// To choose the format of a config field, declare it as a FormattedDuration and set its format in the default config.
+// Loading the field with BindFlags, LoadEnv, UnmarshalJSON, or UnmarshalTOML only replaces the value, so the format is
+// kept. Merge copies whole fields, so the format of the source which wins is used:
+//
+//	conf := Config{Timeout: optional.NoDuration().WithFormat(optional.DURATION_ISO8601_FORMAT)}
+//	err := optional.LoadEnv(&conf)
+type FormattedDuration struct {
+	Duration
+	// StringFormat and DataFormat are one of the DURATION_*_FORMAT constants, used by String and MarshalText
+	// respectively. Empty formats are the same as DURATION_GO_FORMAT.
+	StringFormat string
+	DataFormat   string
+	// Precision rounds durations to a multiple of itself in String, e.g. time.Millisecond prints 1.234s rather than
+	// 1.234567891s. Zero means no rounding. MarshalText never rounds, so no data is lost.
+	Precision time.Duration
+}
+
+// WithFormat returns a copy of the FormattedDuration which uses format for both String and MarshalText.
+func (o FormattedDuration) WithFormat(format string) FormattedDuration {
+	o.StringFormat = format
+	o.DataFormat = format
+	return o
+}
+
+// WithPrecision returns a copy of the FormattedDuration which rounds to a multiple of precision in String.
+func (o FormattedDuration) WithPrecision(precision time.Duration) FormattedDuration {
+	o.Precision = precision
+	return o
+}
+
+func (o FormattedDuration) String() string {
+	tmp, ok := o.Get()
+	if !ok {
+		return o.Duration.String()
+	}
+	if o.Precision > 0 {
+		tmp = tmp.Round(o.Precision)
+	}
+	return formatDuration(tmp, o.StringFormat)
+}
+
+func (o FormattedDuration) MarshalText() (text []byte, err error) {
+	tmp, ok := o.Get()
+	if !ok {
+		return o.Duration.MarshalText()
+	}
+	return []byte(formatDuration(tmp, o.DataFormat)), nil
+}
+
+func (o FormattedDuration) MarshalJSON() ([]byte, error) {
+	if o.IsNone() {
+		return o.Duration.MarshalJSON()
+	}
+	tmp, err := o.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(tmp))
+}
+
+// MarshalTOML writes the duration as a string in DataFormat, the same as MarshalText.
+func (o FormattedDuration) MarshalTOML() ([]byte, error) {
+	tmp, err := o.MarshalText()
+	return []byte(tomlQuote(string(tmp))), err
+}
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond, // U+00B5 = micro symbol
+	"μs": time.Microsecond, // U+03BC = Greek letter mu
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  day,
+	"w":  week,
+}
+
+var intervalUnits = map[string]time.Duration{
+	"microsecond": time.Microsecond, "microseconds": time.Microsecond, "us": time.Microsecond,
+	"millisecond": time.Millisecond, "milliseconds": time.Millisecond, "ms": time.Millisecond,
+	"msec": time.Millisecond, "msecs": time.Millisecond,
+	"second": time.Second, "seconds": time.Second, "sec": time.Second, "secs": time.Second,
+	"minute": time.Minute, "minutes": time.Minute, "min": time.Minute, "mins": time.Minute,
+	"hour": time.Hour, "hours": time.Hour,
+	"day": day, "days": day,
+	"week": week, "weeks": week,
+}
+
+// parseDuration accepts everything time.ParseDuration does, plus the units d (24 hours) and w (7 days), ISO 8601
+// durations such as P1DT2H, and Postgres intervals such as "1 day 02:03:04". Years and months are rejected since
+// they don't have a fixed length.
+func parseDuration(s string) (time.Duration, error) {
+	d, goErr := time.ParseDuration(s)
+	if goErr == nil {
+		return d, nil
+	}
+
+	trimmed := strings.TrimLeft(s, "+-")
+	switch {
+	case strings.HasPrefix(trimmed, "P"):
+		return parseISO8601Duration(s)
+	case strings.ContainsAny(s, " :"):
+		return parseInterval(s)
+	case strings.ContainsAny(trimmed, "dw"):
+		return parseExtendedDuration(s)
+	default:
+		return 0, goErr
+	}
+}
+
+// scaleDuration multiplies the decimal number num by unit, truncating any fraction of a nanosecond.
+func scaleDuration(num string, unit time.Duration) (time.Duration, error) {
+	r, ok := new(big.Rat).SetString(strings.Replace(num, ",", ".", 1))
+	if !ok || strings.ContainsAny(num, "/eE") {
+		return 0, fmt.Errorf("invalid number %q in duration", num)
+	}
+
+	r.Mul(r, new(big.Rat).SetInt64(int64(unit)))
+	ns := new(big.Int).Quo(r.Num(), r.Denom())
+	if !ns.IsInt64() {
+		return 0, fmt.Errorf("duration %s overflows", num)
+	}
+	return time.Duration(ns.Int64()), nil
+}
+
+// sumDurations adds up parts, returning an error on overflow, and negates the result if neg is true.
+func sumDurations(parts []time.Duration, neg bool) (time.Duration, error) {
+	var total time.Duration
+	for _, p := range parts {
+		next := total + p
+		if (p > 0 && next < total) || (p < 0 && next > total) {
+			return 0, optionalError("duration overflows")
+		}
+		total = next
+	}
+	if neg {
+		return -total, nil
+	}
+	return total, nil
+}
+
+// parseExtendedDuration parses go style durations which may also use the units d and w, e.g. 1w2d3h.
+func parseExtendedDuration(s string) (time.Duration, error) {
+	orig := s
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+
+	var parts []time.Duration
+	for s != "" {
+		i := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' })
+		if i <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+		num := s[:i]
+		s = s[i:]
+
+		j := strings.IndexFunc(s, func(r rune) bool { return unicode.IsDigit(r) || r == '.' })
+		if j < 0 {
+			j = len(s)
+		}
+		unit, ok := durationUnits[s[:j]]
+		if !ok {
+			return 0, fmt.Errorf("unknown unit %q in duration %q", s[:j], orig)
+		}
+		s = s[j:]
+
+		d, err := scaleDuration(num, unit)
+		if err != nil {
+			return 0, err
+		}
+		parts = append(parts, d)
+	}
+	return sumDurations(parts, neg)
+}
+
+// parseISO8601Duration parses durations such as P1W, P2DT3H4M5.5S, or -PT30M.
+func parseISO8601Duration(s string) (time.Duration, error) {
+	orig := s
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimLeft(s, "+-"), "P")
+	if s == "" {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", orig)
+	}
+
+	var parts []time.Duration
+	inTime := false
+	for s != "" {
+		if s[0] == 'T' {
+			if inTime {
+				return 0, fmt.Errorf("invalid ISO 8601 duration %q", orig)
+			}
+			inTime = true
+			s = s[1:]
+			if s == "" {
+				// The T designator must be followed by at least one time component.
+				return 0, fmt.Errorf("invalid ISO 8601 duration %q", orig)
+			}
+			continue
+		}
+
+		i := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' && r != ',' })
+		if i <= 0 {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q", orig)
+		}
+		num, designator := s[:i], s[i]
+		s = s[i+1:]
+
+		var unit time.Duration
+		switch {
+		case !inTime && designator == 'W':
+			unit = week
+		case !inTime && designator == 'D':
+			unit = day
+		case inTime && designator == 'H':
+			unit = time.Hour
+		case inTime && designator == 'M':
+			unit = time.Minute
+		case inTime && designator == 'S':
+			unit = time.Second
+		case !inTime && (designator == 'Y' || designator == 'M'):
+			return 0, fmt.Errorf("ISO 8601 duration %q uses years or months, which have no fixed length", orig)
+		default:
+			return 0, fmt.Errorf("invalid designator %q in ISO 8601 duration %q", designator, orig)
+		}
+
+		d, err := scaleDuration(num, unit)
+		if err != nil {
+			return 0, err
+		}
+		parts = append(parts, d)
+	}
+	return sumDurations(parts, neg)
+}
+
+// parseInterval parses intervals in the formats Postgres outputs, such as "3 days 04:05:06.5", "-1 days +02:00:00",
+// "1 week", or "@ 2 days 3 hours ago".
+func parseInterval(s string) (time.Duration, error) {
+	fields := strings.Fields(s)
+	neg := false
+	if len(fields) > 0 && fields[0] == "@" {
+		fields = fields[1:]
+	}
+	if len(fields) > 0 && fields[len(fields)-1] == "ago" {
+		neg = true
+		fields = fields[:len(fields)-1]
+	}
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("invalid interval %q", s)
+	}
+
+	var parts []time.Duration
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if strings.Contains(f, ":") {
+			d, err := parseClock(f)
+			if err != nil {
+				return 0, fmt.Errorf("invalid interval %q: %w", s, err)
+			}
+			parts = append(parts, d)
+			continue
+		}
+
+		if i+1 >= len(fields) {
+			return 0, fmt.Errorf("invalid interval %q: %q has no unit", s, f)
+		}
+		unitName := strings.ToLower(fields[i+1])
+		i++
+
+		if strings.HasPrefix(unitName, "year") || strings.HasPrefix(unitName, "mon") {
+			return 0, fmt.Errorf("interval %q uses years or months, which have no fixed length", s)
+		}
+		unit, ok := intervalUnits[unitName]
+		if !ok {
+			return 0, fmt.Errorf("unknown unit %q in interval %q", unitName, s)
+		}
+
+		fneg := strings.HasPrefix(f, "-")
+		d, err := scaleDuration(strings.TrimLeft(f, "+-"), unit)
+		if err != nil {
+			return 0, err
+		}
+		if fneg {
+			d = -d
+		}
+		parts = append(parts, d)
+	}
+	return sumDurations(parts, neg)
+}
+
+// parseClock parses [+-]HH:MM[:SS[.fraction]].
+func parseClock(s string) (time.Duration, error) {
+	neg := strings.HasPrefix(s, "-")
+	pieces := strings.Split(strings.TrimLeft(s, "+-"), ":")
+	if len(pieces) < 2 || len(pieces) > 3 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+
+	units := []time.Duration{time.Hour, time.Minute, time.Second}
+	var parts []time.Duration
+	for i, p := range pieces {
+		if p == "" || (i < len(pieces)-1 && strings.Contains(p, ".")) {
+			return 0, fmt.Errorf("invalid time %q", s)
+		}
+		d, err := scaleDuration(p, units[i])
+		if err != nil {
+			return 0, err
+		}
+		parts = append(parts, d)
+	}
+	return sumDurations(parts, neg)
+}
+
+// formatDuration writes d in one of the Duration output formats.
+func formatDuration(d time.Duration, format string) string {
+	switch format {
+	case DURATION_DAYS_FORMAT:
+		return formatDaysDuration(d)
+	case DURATION_ISO8601_FORMAT:
+		return formatISO8601Duration(d)
+	default:
+		return d.String()
+	}
+}
+
+// absDuration returns the magnitude of d as a uint64, which can hold the magnitude of math.MinInt64.
+func absDuration(d time.Duration) (u uint64, neg bool) {
+	if d < 0 {
+		return uint64(-(d + 1)) + 1, true
+	}
+	return uint64(d), false
+}
+
+func formatDaysDuration(d time.Duration) string {
+	u, neg := absDuration(d)
+	days, rest := u/uint64(day), u%uint64(day)
+	if days == 0 {
+		return d.String()
+	}
+
+	var b strings.Builder
+	if neg {
+		b.WriteByte('-')
+	}
+	b.WriteString(strconv.FormatUint(days, 10))
+	b.WriteByte('d')
+	if rest != 0 {
+		b.WriteString(time.Duration(rest).String())
+	}
+	return b.String()
+}
+
+func formatISO8601Duration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+
+	u, neg := absDuration(d)
+	var b strings.Builder
+	if neg {
+		b.WriteByte('-')
+	}
+	b.WriteByte('P')
+
+	if days := u / uint64(day); days > 0 {
+		b.WriteString(strconv.FormatUint(days, 10) + "D")
+	}
+	u %= uint64(day)
+	if u == 0 {
+		return b.String()
+	}
+
+	b.WriteByte('T')
+	if hours := u / uint64(time.Hour); hours > 0 {
+		b.WriteString(strconv.FormatUint(hours, 10) + "H")
+	}
+	u %= uint64(time.Hour)
+	if minutes := u / uint64(time.Minute); minutes > 0 {
+		b.WriteString(strconv.FormatUint(minutes, 10) + "M")
+	}
+	u %= uint64(time.Minute)
+	if u > 0 {
+		secs := strconv.FormatUint(u/uint64(time.Second), 10)
+		if frac := u % uint64(time.Second); frac > 0 {
+			secs += strings.TrimRight(fmt.Sprintf(".%09d", frac), "0")
+		}
+		b.WriteString(secs + "S")
+	}
+	return b.String()
+}
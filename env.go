@@ -0,0 +1,135 @@
+package optional
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go-simpler.org/env"
+)
+
+// settable is satisfied by pointers to any LoadableOptional. It is used to find loadable fields through reflection
+// without needing to know the type parameter.
+type settable interface {
+	someable
+	Set(string) error
+}
+
+// EnvOption configures the behavior of LoadEnv.
+type EnvOption func(*envLoader)
+
+// WithEnvPrefix prepends prefix to the name of every variable looked up by LoadEnv. An underscore is added between the
+// prefix and the variable name, so WithEnvPrefix("APP") with a field tagged `env:"PORT"` reads APP_PORT.
+func WithEnvPrefix(prefix string) EnvOption {
+	return func(l *envLoader) {
+		l.prefix = prefix
+	}
+}
+
+// WithEnvSource sets where LoadEnv looks up variables. The default is env.OS, which uses os.LookupEnv. env.Map is
+// convenient for tests.
+func WithEnvSource(source env.Source) EnvOption {
+	return func(l *envLoader) {
+		l.source = source
+	}
+}
+
+// WithEnvSecretReferences lets Secret fields be given references such as file:///run/secrets/db_password or
+// env:OTHER_VAR, the same as a Secret returned by Secret.WithReferences. Without it, variables are always taken as the
+// secret itself. The NAME_FILE convention works either way.
+func WithEnvSecretReferences() EnvOption {
+	return func(l *envLoader) {
+		l.secretRefs = true
+	}
+}
+
+type envLoader struct {
+	prefix     string
+	source     env.Source
+	secretRefs bool
+	errs       []error
+}
+
+// LoadEnv fills every LoadableOptional field of the struct pointed to by cfg which has an `env:"NAME"` tag by passing
+// the value of the environment variable NAME to the field's Set method. Fields whose variable is not set are left
+// untouched, so a None stays None rather than becoming the zero value of the type.
+//
+// Secret fields may instead be given the path of a file holding the secret in NAME_FILE. NAME takes priority if both
+// are set. The file is read lazily, the same as a file reference passed to SecretRef.
+//
+// Nested structs are walked recursively and their variables are prefixed with the nested struct's env tag, or the
+// upper cased field name if there is no tag, joined with an underscore. For example, Port in a nested struct field
+// named TLS is read from TLS_PORT.
+//
+// Every field is attempted even if an earlier one fails to parse. All errors encountered are returned together as a
+// single error built with errors.Join, each naming the variable it came from.
+func LoadEnv(cfg any, opts ...EnvOption) error {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("env config must be a non-nil pointer to a struct, got %T", cfg)
+	}
+
+	l := &envLoader{source: env.OS}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	l.loadStruct(v.Elem(), l.prefix)
+	return errors.Join(l.errs...)
+}
+
+func (l *envLoader) loadStruct(v reflect.Value, prefix string) {
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tag, hasTag := sf.Tag.Lookup("env")
+		name, _, _ := strings.Cut(tag, ",")
+
+		field := v.Field(i)
+		if s, ok := field.Addr().Interface().(settable); ok {
+			if !hasTag || name == "" {
+				continue
+			}
+			if prefix != "" {
+				name = prefix + "_" + name
+			}
+
+			secret, isSecret := s.(*Secret)
+			val, ok := l.source.LookupEnv(name)
+			if !ok {
+				path, hasFile := l.source.LookupEnv(name + "_FILE")
+				if isSecret && hasFile {
+					// Secrets follow the common NAME_FILE convention, where the variable holds the path of a file
+					// containing the secret rather than the secret itself.
+					secret.setRef(fileSecretRef(path))
+				}
+				continue
+			}
+
+			var err error
+			if isSecret {
+				err = secret.unmarshalText([]byte(val), secret.refs || l.secretRefs)
+			} else if a, ok := s.(accumulator); ok {
+				// A variable holds the whole list, so it replaces a default rather than adding to it.
+				err = a.UnmarshalText([]byte(val))
+			} else {
+				err = s.Set(val)
+			}
+			if err != nil {
+				l.errs = append(l.errs, fmt.Errorf("env %s: %w", name, err))
+			}
+		} else if field.Kind() == reflect.Struct {
+			if name == "" {
+				name = strings.ToUpper(sf.Name)
+			}
+			if prefix != "" {
+				name = prefix + "_" + name
+			}
+			l.loadStruct(field, name)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package optional_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brnsampson/optional"
+	"go-simpler.org/env"
+	"gotest.tools/v3/assert"
+)
+
+type envTestTLS struct {
+	Enabled optional.Bool `env:"ENABLED"`
+	Cert    optional.Str  `env:"CERT"`
+}
+
+type envTestConfig struct {
+	Host     optional.Str     `env:"HOST"`
+	Port     optional.Int16   `env:"PORT"`
+	Ratio    optional.Float64 `env:"RATIO"`
+	Timeout  optional.Duration
+	Started  optional.Time   `env:"STARTED"`
+	Password optional.Secret `env:"PASSWORD"`
+	TLS      envTestTLS
+	Other    envTestTLS `env:"ALT"`
+}
+
+func TestLoadEnv(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	source := env.Map{
+		"APP_HOST":        "localhost",
+		"APP_PORT":        "8080",
+		"APP_STARTED":     now.Format(time.RFC3339),
+		"APP_PASSWORD":    "hunter2",
+		"APP_TLS_ENABLED": "true",
+		"APP_ALT_CERT":    "/etc/cert.pem",
+		"TIMEOUT":         "10s",
+	}
+
+	var conf envTestConfig
+	err := optional.LoadEnv(&conf, optional.WithEnvPrefix("APP"), optional.WithEnvSource(source))
+	assert.NilError(t, err)
+
+	assert.Assert(t, conf.Host.Match("localhost"))
+	assert.Assert(t, conf.Port.Match(8080))
+	assert.Assert(t, conf.Password.Match("hunter2"))
+	assert.Assert(t, conf.TLS.Enabled.Match(true))
+	assert.Assert(t, conf.Other.Cert.Match("/etc/cert.pem"))
+	started, ok := conf.Started.Get()
+	assert.Assert(t, ok)
+	assert.Assert(t, now.Equal(started))
+
+	// Unset variables and untagged fields stay None
+	assert.Assert(t, conf.Ratio.IsNone())
+	assert.Assert(t, conf.Timeout.IsNone())
+	assert.Assert(t, conf.TLS.Cert.IsNone())
+	assert.Assert(t, conf.Other.Enabled.IsNone())
+}
+
+func TestLoadEnvAggregatesErrors(t *testing.T) {
+	source := env.Map{
+		"HOST":    "localhost",
+		"PORT":    "99999",
+		"STARTED": "not a time",
+		"RATIO":   "0.5",
+	}
+
+	var conf envTestConfig
+	err := optional.LoadEnv(&conf, optional.WithEnvSource(source))
+	assert.ErrorContains(t, err, "env PORT")
+	assert.ErrorContains(t, err, "env STARTED")
+
+	var numErr *strconv.NumError
+	assert.Assert(t, errors.As(err, &numErr))
+
+	// Fields without errors are still loaded
+	assert.Assert(t, conf.Host.Match("localhost"))
+	assert.Assert(t, conf.Ratio.Match(0.5))
+	assert.Assert(t, conf.Port.IsNone())
+}
+
+func TestLoadEnvRequiresStructPointer(t *testing.T) {
+	var conf envTestConfig
+	err := optional.LoadEnv(conf)
+	assert.Assert(t, err != nil)
+}
+
+func TestLoadEnvSecretFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db_password")
+	assert.NilError(t, os.WriteFile(path, []byte("hunter2\n"), 0o600))
+
+	var cfg struct {
+		Password optional.Secret `env:"DB_PASSWORD"`
+		Token    optional.Secret `env:"TOKEN"`
+	}
+	source := env.Map{"DB_PASSWORD_FILE": path, "TOKEN": "literal", "TOKEN_FILE": path}
+	assert.NilError(t, optional.LoadEnv(&cfg, optional.WithEnvSource(source)))
+
+	assert.Assert(t, cfg.Password.Match("hunter2"))
+	ref, ok := cfg.Password.Reference()
+	assert.Assert(t, ok)
+	assert.Assert(t, strings.HasPrefix(ref, "file://"))
+
+	// The variable itself takes priority over the file
+	assert.Assert(t, cfg.Token.Match("literal"))
+}
@@ -0,0 +1,164 @@
+package file
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/fs"
+)
+
+// DefaultPrivateKeyPerms is the most permissive set of file permissions that PrivateKey.ReadPrivateKey will accept.
+const DefaultPrivateKeyPerms fs.FileMode = 0600
+
+// Cert is an optional path to a PEM encoded certificate or certificate chain.
+type Cert struct {
+	File
+}
+
+func SomeCert(path string) Cert {
+	return Cert{SomeFile(path)}
+}
+
+func NoCert() Cert {
+	return Cert{NoFile()}
+}
+
+// Type overrides the Type() method from the inner File. Part of the flag.Value interface.
+func (c Cert) Type() string {
+	return "Cert"
+}
+
+func (c Cert) String() string {
+	if c.IsNone() {
+		return "None[Cert]"
+	}
+	return c.File.String()
+}
+
+// ReadCerts reads and parses every CERTIFICATE block in the file. Any other PEM blocks are skipped. An error is
+// returned if the file contains no certificates at all.
+func (c Cert) ReadCerts() ([]*x509.Certificate, error) {
+	data, err := c.ReadFile()
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found in %s", c.File)
+	}
+	return certs, nil
+}
+
+// CertPool reads the certificates in the file into a new x509.CertPool. This is convenient for files containing CA
+// certificates which should be used as tls.Config.RootCAs or tls.Config.ClientCAs.
+func (c Cert) CertPool() (*x509.CertPool, error) {
+	certs, err := c.ReadCerts()
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool, nil
+}
+
+// TLSCertificate loads the certificate chain along with the matching private key into a tls.Certificate, ready to
+// be used in a tls.Config. The permissions of the key file are checked the same way as PrivateKey.ReadPrivateKey.
+func (c Cert) TLSCertificate(key PrivateKey) (tls.Certificate, error) {
+	certPEM, err := c.ReadFile()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	keyPEM, err := key.ReadPEM()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+// PrivateKey is an optional path to a PEM encoded private key. Since private keys are sensitive, the file's
+// permissions are checked before it is read.
+type PrivateKey struct {
+	File
+}
+
+func SomePrivateKey(path string) PrivateKey {
+	return PrivateKey{SomeFile(path)}
+}
+
+func NoPrivateKey() PrivateKey {
+	return PrivateKey{NoFile()}
+}
+
+// Type overrides the Type() method from the inner File. Part of the flag.Value interface.
+func (k PrivateKey) Type() string {
+	return "PrivateKey"
+}
+
+func (k PrivateKey) String() string {
+	if k.IsNone() {
+		return "None[PrivateKey]"
+	}
+	return k.File.String()
+}
+
+// ReadPEM returns the raw contents of the key file after checking that it is not more permissive than
+// DefaultPrivateKeyPerms.
+func (k PrivateKey) ReadPEM() ([]byte, error) {
+	if err := k.CheckPerms(DefaultPrivateKeyPerms); err != nil {
+		return nil, err
+	}
+	return k.ReadFile()
+}
+
+// ReadPrivateKey reads and parses the first private key found in the file. PKCS #1, PKCS #8, and SEC 1 EC keys
+// are supported.
+func (k PrivateKey) ReadPrivateKey() (crypto.PrivateKey, error) {
+	data, err := k.ReadPEM()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		switch block.Type {
+		case "PRIVATE KEY":
+			return x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			return x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			return x509.ParseECPrivateKey(block.Bytes)
+		}
+	}
+
+	return nil, fmt.Errorf("no private key found in %s", k.File)
+}
@@ -0,0 +1,105 @@
+package file_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/brnsampson/optional/file"
+	"gotest.tools/v3/assert"
+)
+
+func writeTestKeyPair(t *testing.T, dir string) (certPath, keyPath string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "optional.test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	assert.NilError(t, err)
+
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NilError(t, err)
+
+	certPath = filepath.Join(dir, "cert.pem")
+	keyPath = filepath.Join(dir, "key.pem")
+	err = os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	assert.NilError(t, err)
+	err = os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}), 0600)
+	assert.NilError(t, err)
+	return certPath, keyPath
+}
+
+func TestCertType(t *testing.T) {
+	c := file.SomeCert("/tmp/cert.pem")
+	assert.Equal(t, reflect.TypeOf(c).Name(), c.Type())
+	k := file.SomePrivateKey("/tmp/key.pem")
+	assert.Equal(t, reflect.TypeOf(k).Name(), k.Type())
+}
+
+func TestCertReadCerts(t *testing.T) {
+	certPath, _ := writeTestKeyPair(t, t.TempDir())
+
+	c := file.SomeCert(certPath)
+	certs, err := c.ReadCerts()
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(certs))
+	assert.Equal(t, "optional.test", certs[0].Subject.CommonName)
+
+	pool, err := c.CertPool()
+	assert.NilError(t, err)
+	assert.Assert(t, pool != nil)
+
+	_, err = file.NoCert().ReadCerts()
+	assert.Assert(t, err != nil)
+}
+
+func TestPrivateKeyRead(t *testing.T) {
+	_, keyPath := writeTestKeyPair(t, t.TempDir())
+
+	k := file.SomePrivateKey(keyPath)
+	key, err := k.ReadPrivateKey()
+	assert.NilError(t, err)
+	_, ok := key.(*ecdsa.PrivateKey)
+	assert.Assert(t, ok)
+
+	// Keys readable by others are refused
+	err = os.Chmod(keyPath, 0644)
+	assert.NilError(t, err)
+	_, err = k.ReadPrivateKey()
+	assert.Assert(t, err != nil)
+}
+
+func TestCertTLSCertificate(t *testing.T) {
+	certPath, keyPath := writeTestKeyPair(t, t.TempDir())
+
+	type Config struct {
+		TLSCert file.Cert
+		TLSKey  file.PrivateKey
+	}
+	conf := Config{file.SomeCert(certPath), file.SomePrivateKey(keyPath)}
+
+	cert, err := conf.TLSCert.TLSCertificate(conf.TLSKey)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(cert.Certificate))
+
+	_, err = file.NoCert().TLSCertificate(conf.TLSKey)
+	assert.Assert(t, err != nil)
+	_, err = conf.TLSCert.TLSCertificate(file.NoPrivateKey())
+	assert.Assert(t, err != nil)
+}
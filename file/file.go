@@ -0,0 +1,142 @@
+// Package file provides optional types for values which refer to files on disk, such as config files, certificates,
+// and private keys. The optional only ever holds the path; the contents of the file are read lazily when asked for.
+package file
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/brnsampson/optional"
+)
+
+// File is an optional path to a file. It implements optional.LoadableOptional[string] so it can be loaded from flags,
+// env vars, and config files the same way as an optional.Str, but also provides some helpers for checking on and
+// reading the file that the path refers to.
+type File struct {
+	optional.Option[string]
+}
+
+func SomeFile(path string) File {
+	return File{optional.Some(path)}
+}
+
+func NoFile() File {
+	return File{optional.None[string]()}
+}
+
+func (f File) Type() string {
+	return "File"
+}
+
+func (f *File) Set(str string) error {
+	return f.UnmarshalText([]byte(str))
+}
+
+func (f File) String() string {
+	if f.IsNone() {
+		return "None[File]"
+	} else {
+		tmp, ok := f.Get()
+		if !ok {
+			return "Error[File]"
+		}
+		return tmp
+	}
+}
+
+func (f File) MarshalText() (text []byte, err error) {
+	if f.IsNone() {
+		return []byte("None"), nil
+	} else {
+		tmp, ok := f.Get()
+		var err error
+		if !ok {
+			err = fmt.Errorf("attempted to Get %s with None value", f.Type())
+		}
+		return []byte(tmp), err
+	}
+}
+
+func (f *File) UnmarshalText(text []byte) error {
+	tmp := string(text)
+	if tmp == "None" || tmp == "none" || tmp == "null" || tmp == "nil" {
+		f.Clear()
+	} else {
+		f.Replace(tmp)
+	}
+	return nil
+}
+
+// Path returns the path held by the File, or an error if the File is None.
+func (f File) Path() (string, error) {
+	path, ok := f.Get()
+	if !ok {
+		return "", fmt.Errorf("%s has no path set", f.Type())
+	}
+	return path, nil
+}
+
+// Abs returns an absolute representation of the path held by the File. See filepath.Abs.
+func (f File) Abs() (string, error) {
+	path, err := f.Path()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(path)
+}
+
+// Stat returns the fs.FileInfo for the file the path refers to. None values return an error.
+func (f File) Stat() (fs.FileInfo, error) {
+	path, err := f.Path()
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(path)
+}
+
+// Exists returns true if the File is Some and the path refers to an existing regular file. Any error encountered
+// while checking, including the path pointing at a directory, is treated as the file not existing.
+func (f File) Exists() bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode().IsRegular()
+}
+
+// CheckPerms returns an error if the file grants any permission bits which are not also present in allowed. For
+// example, CheckPerms(0600) fails for a file which is group or world readable. Files which cannot be stat'd return
+// the error from Stat.
+func (f File) CheckPerms(allowed fs.FileMode) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	perms := info.Mode().Perm()
+	if extra := perms &^ allowed.Perm(); extra != 0 {
+		return fmt.Errorf("file %s has permissions %s, which is more permissive than %s", info.Name(), perms, allowed.Perm())
+	}
+	return nil
+}
+
+// Open opens the file for reading. It is the caller's responsibility to close the returned *os.File.
+func (f File) Open() (*os.File, error) {
+	path, err := f.Path()
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// ReadFile reads the entire contents of the file. The file is read each time this is called, so changes made to the
+// file on disk will be picked up by subsequent reads.
+func (f File) ReadFile() ([]byte, error) {
+	path, err := f.Path()
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,88 @@
+package file_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/brnsampson/optional"
+	"github.com/brnsampson/optional/file"
+	"gotest.tools/v3/assert"
+)
+
+func TestFileIsLoadableOptional(t *testing.T) {
+	// The real test is if we get compiler errors because &f does not implement LoadableOptional
+	f := file.SomeFile("/tmp/test")
+	var lo optional.LoadableOptional[string] = &f
+	assert.Assert(t, lo.IsSome())
+}
+
+func TestFileType(t *testing.T) {
+	f := file.SomeFile("/tmp/test")
+	assert.Equal(t, reflect.TypeOf(f).Name(), f.Type())
+}
+
+func TestFileString(t *testing.T) {
+	path := "/tmp/test"
+	f := file.SomeFile(path)
+	assert.Equal(t, path, f.String())
+	assert.Equal(t, "None[File]", file.NoFile().String())
+}
+
+func TestFileUnmarshalText(t *testing.T) {
+	path := "/tmp/test"
+	f := file.NoFile()
+	err := f.Set(path)
+	assert.NilError(t, err)
+	assert.Assert(t, f.Match(path))
+
+	s, err := f.MarshalText()
+	assert.NilError(t, err)
+	assert.Equal(t, path, string(s))
+
+	err = f.UnmarshalText([]byte("null"))
+	assert.NilError(t, err)
+	assert.Assert(t, f.IsNone())
+}
+
+func TestFileExistsAndRead(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.txt")
+	contents := "some file contents"
+
+	f := file.SomeFile(path)
+	assert.Assert(t, !f.Exists())
+	_, err := f.ReadFile()
+	assert.Assert(t, err != nil)
+
+	err = os.WriteFile(path, []byte(contents), 0644)
+	assert.NilError(t, err)
+	assert.Assert(t, f.Exists())
+
+	data, err := f.ReadFile()
+	assert.NilError(t, err)
+	assert.Equal(t, contents, string(data))
+
+	// Directories are not files
+	assert.Assert(t, !file.SomeFile(dir).Exists())
+
+	// None never exists
+	none := file.NoFile()
+	assert.Assert(t, !none.Exists())
+	_, err = none.ReadFile()
+	assert.Assert(t, err != nil)
+}
+
+func TestFileCheckPerms(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.txt")
+	err := os.WriteFile(path, []byte("test"), 0600)
+	assert.NilError(t, err)
+	err = os.Chmod(path, 0640)
+	assert.NilError(t, err)
+
+	f := file.SomeFile(path)
+	assert.NilError(t, f.CheckPerms(0644))
+	assert.NilError(t, f.CheckPerms(0640))
+	assert.Assert(t, f.CheckPerms(0600) != nil)
+}
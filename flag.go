@@ -0,0 +1,111 @@
+package optional
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// optionalFlag is satisfied by pointers to any LoadableOptional, which all implement flag.Value.
+type optionalFlag interface {
+	someable
+	flag.Value
+}
+
+// BindFlags registers every LoadableOptional field of the struct pointed to by cfg which has a `flag:"name"` tag with
+// fs. The `usage:"..."` tag, if present, is used as the flag's usage message. Since the fields themselves are used as
+// the flag.Value, any flag which is not passed on the command line is left untouched. That means there is no need for
+// the ClearIfMatch magic value trick to tell if a flag was set; after fs.Parse any field that is still None was not
+// given by the user, and the struct can be passed straight to Merge along with the configs from other sources.
+//
+// Nested structs are walked recursively and their flags are prefixed with the nested struct's flag tag, or the lower
+// cased field name if there is no tag, joined with a dash. For example, port in a nested struct field named TLS is
+// registered as -tls-port.
+//
+// Bool fields are registered as boolean flags, so a bare -debug sets Some(true). Adding the negatable option, as in
+// `flag:"debug,negatable"`, also registers -no-debug which sets Some(false).
+//
+// An error is returned if cfg is not a pointer to a struct or if a flag with the same name is already defined in fs.
+func BindFlags(fs *flag.FlagSet, cfg any) error {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("flag config must be a non-nil pointer to a struct, got %T", cfg)
+	}
+	return bindStruct(fs, v.Elem(), "")
+}
+
+func bindStruct(fs *flag.FlagSet, v reflect.Value, prefix string) error {
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tag, hasTag := sf.Tag.Lookup("flag")
+		name, _, _ := strings.Cut(tag, ",")
+
+		field := v.Field(i)
+		if f, ok := field.Addr().Interface().(optionalFlag); ok {
+			if !hasTag || name == "" {
+				continue
+			}
+			if prefix != "" {
+				name = prefix + "-" + name
+			}
+
+			if fs.Lookup(name) != nil {
+				return fmt.Errorf("flag -%s is already defined", name)
+			}
+			if a, ok := f.(accumulator); ok {
+				f = &replaceFirstFlag{optionalFlag: f, replace: a.UnmarshalText}
+			}
+			fs.Var(f, name, sf.Tag.Get("usage"))
+
+			if b, ok := f.(*Bool); ok && hasFlagOption(tag, "negatable") {
+				negated := "no-" + name
+				if fs.Lookup(negated) != nil {
+					return fmt.Errorf("flag -%s is already defined", negated)
+				}
+				fs.Var(b.Negated(), negated, fmt.Sprintf("negates -%s", name))
+			}
+		} else if field.Kind() == reflect.Struct {
+			if name == "" {
+				name = strings.ToLower(sf.Name)
+			}
+			if prefix != "" {
+				name = prefix + "-" + name
+			}
+			if err := bindStruct(fs, field, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// replaceFirstFlag wraps flags whose Set adds to the current value, such as a Slice, so that the first use of the flag
+// replaces any default held by the field and only later uses add to it. This is how pflag's slice flags behave.
+type replaceFirstFlag struct {
+	optionalFlag
+	replace func([]byte) error
+	set     bool
+}
+
+func (f *replaceFirstFlag) Set(str string) error {
+	if f.set {
+		return f.optionalFlag.Set(str)
+	}
+	f.set = true
+	return f.replace([]byte(str))
+}
+
+func hasFlagOption(tag, option string) bool {
+	_, opts, _ := strings.Cut(tag, ",")
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package optional_test
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brnsampson/optional"
+	"gotest.tools/v3/assert"
+)
+
+type flagTestTLS struct {
+	Cert optional.Str `flag:"cert" usage:"path to the TLS certificate"`
+}
+
+type flagTestConfig struct {
+	Host    optional.Str      `flag:"host" usage:"address to listen on"`
+	Port    optional.Int      `flag:"port" usage:"port to listen on"`
+	Timeout optional.Duration `flag:"timeout"`
+	Started optional.Time     `flag:"started"`
+	Secret  optional.Secret   `flag:"secret"`
+	Ignored optional.Int
+	TLS     flagTestTLS
+}
+
+func TestBindFlags(t *testing.T) {
+	var conf flagTestConfig
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := optional.BindFlags(fs, &conf)
+	assert.NilError(t, err)
+
+	err = fs.Parse([]string{"-port", "8080", "-timeout=5s", "-tls-cert", "/etc/cert.pem"})
+	assert.NilError(t, err)
+
+	assert.Assert(t, conf.Port.Match(8080))
+	assert.Assert(t, conf.Timeout.Match(5*time.Second))
+	assert.Assert(t, conf.TLS.Cert.Match("/etc/cert.pem"))
+
+	// Flags which were not passed stay None
+	assert.Assert(t, conf.Host.IsNone())
+	assert.Assert(t, conf.Started.IsNone())
+	assert.Assert(t, conf.Secret.IsNone())
+	assert.Assert(t, fs.Lookup("ignored") == nil)
+
+	usage := fs.Lookup("host").Usage
+	assert.Equal(t, "address to listen on", usage)
+}
+
+func TestBindFlagsPrecedence(t *testing.T) {
+	var flags flagTestConfig
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := optional.BindFlags(fs, &flags)
+	assert.NilError(t, err)
+	err = fs.Parse([]string{"-port", "0"})
+	assert.NilError(t, err)
+
+	// An explicit zero from the command line still wins over the file config
+	file := flagTestConfig{Host: optional.SomeStr("example.com"), Port: optional.SomeInt(1443)}
+	var conf flagTestConfig
+	err = optional.Merge(&conf, flags, file)
+	assert.NilError(t, err)
+	assert.Assert(t, conf.Port.Match(0))
+	assert.Assert(t, conf.Host.Match("example.com"))
+}
+
+func TestBindFlagsUsage(t *testing.T) {
+	var conf flagTestConfig
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := optional.BindFlags(fs, &conf)
+	assert.NilError(t, err)
+
+	var buf bytes.Buffer
+	fs.SetOutput(&buf)
+	fs.PrintDefaults()
+	assert.Assert(t, strings.Contains(buf.String(), "port to listen on"))
+	assert.Assert(t, !strings.Contains(buf.String(), "None"))
+}
+
+func TestBindFlagsErrors(t *testing.T) {
+	var conf flagTestConfig
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := optional.BindFlags(fs, conf)
+	assert.Assert(t, err != nil)
+
+	err = optional.BindFlags(fs, &conf)
+	assert.NilError(t, err)
+	err = optional.BindFlags(fs, &conf)
+	assert.Assert(t, err != nil)
+
+	// Parse errors are reported by the flag set
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&bytes.Buffer{})
+	err = optional.BindFlags(fs, &conf)
+	assert.NilError(t, err)
+	err = fs.Parse([]string{"-port", "not a number"})
+	assert.Assert(t, err != nil)
+}
+
+func TestBindFlagsBool(t *testing.T) {
+	type config struct {
+		Debug   optional.Bool `flag:"debug,negatable" usage:"enable debug logging"`
+		Verbose optional.Bool `flag:"verbose"`
+		Color   optional.Bool `flag:"color,negatable"`
+	}
+
+	var conf config
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := optional.BindFlags(fs, &conf)
+	assert.NilError(t, err)
+	assert.Assert(t, fs.Lookup("no-verbose") == nil)
+
+	err = fs.Parse([]string{"-debug", "-no-color"})
+	assert.NilError(t, err)
+	assert.Assert(t, conf.Debug.Match(true))
+	assert.Assert(t, conf.Color.Match(false))
+	assert.Assert(t, conf.Verbose.IsNone())
+}
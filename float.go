@@ -67,6 +67,31 @@ func (o *Float32) UnmarshalText(text []byte) error {
 	return nil
 }
 
+// Scan implements database/sql.Scanner interface. int64, float64, bool, string, and []byte values are all accepted
+// as long as the value fits into float32.
+func (o *Float32) Scan(src any) error {
+	if src == nil {
+		// NULL value row
+		o.Clear()
+		return nil
+	}
+	val, err := scanNumber[float32](src)
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
+	}
+	_ = o.Replace(val)
+	return nil
+}
+
+// Value implements the database/sql/driver.Valuer interface
+func (o Float32) Value() (driver.Value, error) {
+	val, ok := o.Get()
+	if ok {
+		return float64(val), nil
+	}
+	return nil, nil
+}
+
 func (o Float32) Add(i Float32) Float32 {
 	a, ok := o.Get()
 	if !ok {
@@ -80,6 +105,12 @@ func (o Float32) Add(i Float32) Float32 {
 	return SomeFloat32(a + b)
 }
 
+// Cmp compares o with other the same as Compare, with None sorting first. It can be passed straight to slices.SortFunc
+// as Float32.Cmp.
+func (o Float32) Cmp(other Float32) int {
+	return Compare(o.Option, other.Option, true)
+}
+
 // 64bit sized floats
 
 type Float64 struct {
@@ -141,23 +172,19 @@ func (o *Float64) UnmarshalText(text []byte) error {
 	return nil
 }
 
-// Scan implements database/sql.Scanner interface.
+// Scan implements database/sql.Scanner interface. int64, float64, bool, string, and []byte values are all accepted
+// as long as the value fits into float64.
 func (o *Float64) Scan(src any) error {
 	if src == nil {
 		// NULL value row
 		o.Clear()
 		return nil
 	}
-	switch t := src.(type) {
-	case float64:
-		_ = o.Replace(t)
-	case string:
-		if err := o.UnmarshalText([]byte(t)); err != nil {
-			return err
-		}
-	default:
-		return fmt.Errorf("converting driver.Value type %T to %s", src, o.Type())
+	val, err := scanNumber[float64](src)
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
 	}
+	_ = o.Replace(val)
 	return nil
 }
 
@@ -182,3 +209,9 @@ func (o Float64) Add(i Float64) Float64 {
 	}
 	return SomeFloat64(a + b)
 }
+
+// Cmp compares o with other the same as Compare, with None sorting first. It can be passed straight to slices.SortFunc
+// as Float64.Cmp.
+func (o Float64) Cmp(other Float64) int {
+	return Compare(o.Option, other.Option, true)
+}
@@ -1,14 +1,16 @@
 module github.com/brnsampson/optional
 
-go 1.21.1
+go 1.23
 
 require (
 	github.com/BurntSushi/toml v1.3.2
 	go-simpler.org/env v0.12.0
+	golang.org/x/crypto v0.21.0
 	gotest.tools/v3 v3.5.1
 )
 
 require (
 	github.com/DATA-DOG/go-sqlmock v1.5.2 // indirect
 	github.com/google/go-cmp v0.5.9 // indirect
+	golang.org/x/sys v0.18.0 // indirect
 )
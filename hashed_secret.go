@@ -0,0 +1,308 @@
+package optional
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// secretsEqual compares a and b in constant time. Both are hashed first so that the time taken does not depend on
+// their lengths either, which subtle.ConstantTimeCompare alone would leak.
+func secretsEqual(a, b []byte) bool {
+	ah := sha256.Sum256(a)
+	bh := sha256.Sum256(b)
+	return subtle.ConstantTimeCompare(ah[:], bh[:]) == 1
+}
+
+// Argon2idParams are the cost parameters used to hash secrets with argon2id. Memory is in KiB.
+type Argon2idParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// Upper bounds on argon2id parameters. A hash decides how much memory and CPU time Verify spends, so hashes read from
+// config files or a database are rejected if they exceed these, rather than letting one crafted hash exhaust the
+// process. The memory limit allows for the first recommended option from RFC 9106, which uses 2 GiB.
+const (
+	MAX_ARGON2ID_MEMORY      uint32 = 2 * 1024 * 1024
+	MAX_ARGON2ID_ITERATIONS  uint32 = 16
+	MAX_ARGON2ID_PARALLELISM uint8  = 32
+	MAX_ARGON2ID_SALT_LENGTH uint32 = 64
+	MAX_ARGON2ID_KEY_LENGTH  uint32 = 128
+)
+
+// MAX_BCRYPT_COST is the highest bcrypt cost accepted, for the same reason. Each step doubles the time Verify takes, and
+// a cost of 15 already takes a few seconds on current hardware.
+const MAX_BCRYPT_COST int = 15
+
+// DefaultArgon2idParams follow the second recommended option from RFC 9106, which uses 64 MiB of memory.
+var DefaultArgon2idParams = Argon2idParams{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 4,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// HashedSecret holds a password hash rather than the secret itself, so that config files and databases can store the
+// hash of an API token instead of the plaintext. Use Verify to check a candidate against it. Hashes are stored in the
+// usual encoded forms, either the PHC string format for argon2id ($argon2id$v=19$m=...,t=...,p=...$salt$hash) or the
+// modular crypt format used by bcrypt ($2a$10$...).
+//
+// Only well formed hashes are accepted by the constructors, Set, Transform, UnmarshalText, UnmarshalJSON, UnmarshalTOML,
+// and Scan. Replace and Default keep the signatures from MutableOptional, so that HashedSecret is a StorableOptional and a
+// LoadableOptional like Secret, and so can not report an error. Verify checks the hash again, so a malformed hash
+// stored through them only ever fails verification. The hash is redacted when printed or logged, the same as a Secret,
+// since it can still be attacked offline.
+type HashedSecret struct {
+	Str
+}
+
+// HashSecret hashes plaintext with argon2id using DefaultArgon2idParams.
+func HashSecret(plaintext string) (HashedSecret, error) {
+	return HashSecretArgon2id(plaintext, DefaultArgon2idParams)
+}
+
+// HashSecretArgon2id hashes plaintext with argon2id using the given parameters and a random salt.
+func HashSecretArgon2id(plaintext string, params Argon2idParams) (HashedSecret, error) {
+	if err := params.validate(); err != nil {
+		return NoHashedSecret(), err
+	}
+
+	salt := make([]byte, params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return NoHashedSecret(), err
+	}
+
+	key := argon2.IDKey([]byte(plaintext), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	encoded := fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, params.Memory, params.Iterations,
+		params.Parallelism, base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+	return HashedSecret{SomeStr(encoded)}, nil
+}
+
+// HashSecretBcrypt hashes plaintext with bcrypt at the given cost. bcrypt.DefaultCost is a reasonable choice. Note that
+// bcrypt can not hash secrets longer than 72 bytes.
+func HashSecretBcrypt(plaintext string, cost int) (HashedSecret, error) {
+	if cost > MAX_BCRYPT_COST {
+		return NoHashedSecret(), fmt.Errorf("bcrypt cost %d exceeds the limit of %d", cost, MAX_BCRYPT_COST)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(plaintext), cost)
+	if err != nil {
+		return NoHashedSecret(), err
+	}
+	return HashedSecret{SomeStr(string(hash))}, nil
+}
+
+// ParseHashedSecret creates a HashedSecret from an existing argon2id or bcrypt hash.
+func ParseHashedSecret(hash string) (HashedSecret, error) {
+	if err := validateHash(hash); err != nil {
+		return NoHashedSecret(), err
+	}
+	return HashedSecret{SomeStr(hash)}, nil
+}
+
+func NoHashedSecret() HashedSecret {
+	return HashedSecret{NoStr()}
+}
+
+// validate checks that params are non-zero where required and within the MAX_ARGON2ID_* bounds.
+func (p Argon2idParams) validate() error {
+	switch {
+	case p.Iterations == 0 || p.Parallelism == 0 || p.KeyLength == 0:
+		return optionalError("argon2id iterations, parallelism, and key length must be non-zero")
+	case p.Memory > MAX_ARGON2ID_MEMORY:
+		return fmt.Errorf("argon2id memory %d KiB exceeds the limit of %d KiB", p.Memory, MAX_ARGON2ID_MEMORY)
+	case p.Iterations > MAX_ARGON2ID_ITERATIONS:
+		return fmt.Errorf("argon2id iterations %d exceed the limit of %d", p.Iterations, MAX_ARGON2ID_ITERATIONS)
+	case p.Parallelism > MAX_ARGON2ID_PARALLELISM:
+		return fmt.Errorf("argon2id parallelism %d exceeds the limit of %d", p.Parallelism, MAX_ARGON2ID_PARALLELISM)
+	case p.SaltLength > MAX_ARGON2ID_SALT_LENGTH:
+		return fmt.Errorf("argon2id salt length %d exceeds the limit of %d", p.SaltLength, MAX_ARGON2ID_SALT_LENGTH)
+	case p.KeyLength > MAX_ARGON2ID_KEY_LENGTH:
+		return fmt.Errorf("argon2id key length %d exceeds the limit of %d", p.KeyLength, MAX_ARGON2ID_KEY_LENGTH)
+	}
+	return nil
+}
+
+// Verify reports if candidate hashes to the stored hash. None and malformed hashes always fail verification.
+func (h HashedSecret) Verify(candidate string) bool {
+	hash, ok := h.Get()
+	if !ok {
+		return false
+	}
+
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false
+		}
+		other := argon2.IDKey([]byte(candidate), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+		return subtle.ConstantTimeCompare(key, other) == 1
+	}
+	if validateHash(hash) != nil {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(candidate)) == nil
+}
+
+// Match is the same as Verify, so that a HashedSecret can be used anywhere a Secret can be matched against a token.
+func (h HashedSecret) Match(candidate string) bool {
+	return h.Verify(candidate)
+}
+
+func (h HashedSecret) Type() string {
+	return "HashedSecret"
+}
+
+// String ALWAYS redact hashes
+func (h HashedSecret) String() string {
+	return "***REDACTED***"
+}
+
+// Format ALWAYS redact hashes no matter what formatting verb or flag is set
+func (h HashedSecret) Format(f fmt.State, verb rune) {
+	_, _ = f.Write([]byte(h.String()))
+}
+
+// LogValue redacts hashes when logging as well.
+func (h HashedSecret) LogValue() slog.Value {
+	return slog.StringValue(h.String())
+}
+
+func (h *HashedSecret) Set(str string) error {
+	return h.UnmarshalText([]byte(str))
+}
+
+// Transform overrides Transform from the inner string so that the result must be a well formed hash as well.
+func (h *HashedSecret) Transform(t Transformer[string]) error {
+	hash, ok := h.Get()
+	if !ok {
+		return nil
+	}
+
+	tmp, err := t(hash)
+	if err != nil {
+		return err
+	}
+	return h.replace(tmp)
+}
+
+// replace stores hash if it is well formed, and otherwise leaves the HashedSecret unchanged.
+func (h *HashedSecret) replace(hash string) error {
+	if err := validateHash(hash); err != nil {
+		return err
+	}
+	h.Str.Replace(hash)
+	return nil
+}
+
+// UnmarshalText only accepts well formed argon2id or bcrypt hashes, or one of the None strings.
+func (h *HashedSecret) UnmarshalText(text []byte) error {
+	tmp := string(text)
+	if isNoneString(tmp) {
+		h.Clear()
+		return nil
+	}
+	return h.replace(tmp)
+}
+
+func (h *HashedSecret) UnmarshalJSON(data []byte) error {
+	var tmp Str
+	if err := tmp.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	hash, ok := tmp.Get()
+	if !ok {
+		h.Clear()
+		return nil
+	}
+	return h.UnmarshalText([]byte(hash))
+}
+
+// UnmarshalTOML only accepts TOML strings holding a well formed hash.
+func (h *HashedSecret) UnmarshalTOML(data any) error {
+	hash, ok := data.(string)
+	if !ok {
+		return fmt.Errorf("cannot unmarshal %T into %s", data, h.Type())
+	}
+	return h.UnmarshalText([]byte(hash))
+}
+
+// Scan implements database/sql.Scanner interface.
+func (h *HashedSecret) Scan(src any) error {
+	if src == nil {
+		// NULL value row
+		h.Clear()
+		return nil
+	}
+	val, err := scanString(src)
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, h.Type(), err)
+	}
+	if err := h.replace(val); err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, h.Type(), err)
+	}
+	return nil
+}
+
+func validateHash(hash string) error {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		_, _, _, err := decodeArgon2id(hash)
+		return err
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return fmt.Errorf("not an argon2id or bcrypt hash: %w", err)
+	}
+	if cost > MAX_BCRYPT_COST {
+		return fmt.Errorf("bcrypt cost %d exceeds the limit of %d", cost, MAX_BCRYPT_COST)
+	}
+	return nil
+}
+
+func decodeArgon2id(hash string) (params Argon2idParams, salt, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, optionalError("malformed argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id hash version: %w", err)
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2id version %d", version)
+	}
+
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism)
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id hash parameters: %w", err)
+	}
+	// Check the encoded lengths before decoding so that an oversized hash is never allocated.
+	params.SaltLength = uint32(base64.RawStdEncoding.DecodedLen(len(parts[4])))
+	params.KeyLength = uint32(base64.RawStdEncoding.DecodedLen(len(parts[5])))
+	if err := params.validate(); err != nil {
+		return params, nil, nil, err
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id salt: %w", err)
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id hash: %w", err)
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
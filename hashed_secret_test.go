@@ -0,0 +1,240 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/brnsampson/optional"
+	"golang.org/x/crypto/bcrypt"
+	"gotest.tools/v3/assert"
+)
+
+// Cheap parameters so the tests run quickly. Never use these for real secrets.
+var testArgon2idParams = optional.Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+// HashedSecret keeps the same interfaces as Secret, so it can be loaded and stored the same way.
+var _ optional.LoadableOptional[string] = &optional.HashedSecret{}
+
+func TestSecretMatchConstantTime(t *testing.T) {
+	s := optional.SomeSecret("hunter2")
+	assert.Assert(t, s.Match("hunter2"))
+	assert.Assert(t, !s.Match("hunter"))
+	assert.Assert(t, !s.Match("hunter22"))
+	assert.Assert(t, !optional.NoSecret().Match(""))
+
+	assert.Assert(t, s.Equal(optional.SomeSecret("hunter2")))
+	assert.Assert(t, !s.Equal(optional.SomeSecret("hunter3")))
+	assert.Assert(t, !s.Equal(optional.NoSecret()))
+	assert.Assert(t, optional.NoSecret().Equal(optional.NoSecret()))
+}
+
+func TestSecretBytesMatch(t *testing.T) {
+	s := optional.SomeSecretBytes([]byte("hunter2"))
+	assert.Assert(t, s.Match([]byte("hunter2")))
+	assert.Assert(t, !s.Match([]byte("hunter3")))
+
+	copied := s
+	assert.Assert(t, s.Equal(copied))
+	assert.Assert(t, s.Equal(optional.SomeSecretBytes([]byte("hunter2"))))
+	assert.Assert(t, !s.Equal(optional.NoSecretBytes()))
+	assert.Assert(t, optional.NoSecretBytes().Equal(optional.NoSecretBytes()))
+
+	// Comparing in both orders at once while writers wait on the buffers must not deadlock
+	other := optional.SomeSecretBytes([]byte("hunter2"))
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				s.Equal(other)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				other.Equal(s)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				assert.NilError(t, s.Set("hunter2"))
+				assert.NilError(t, other.Set("hunter2"))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestHashedSecretArgon2id(t *testing.T) {
+	h, err := optional.HashSecretArgon2id("token", testArgon2idParams)
+	assert.NilError(t, err)
+	assert.Assert(t, h.Verify("token"))
+	assert.Assert(t, h.Match("token"))
+	assert.Assert(t, !h.Verify("wrong"))
+
+	hash, ok := h.Get()
+	assert.Assert(t, ok)
+	assert.Assert(t, strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$"))
+
+	// Salts are random, so hashing the same secret twice gives different hashes
+	other, err := optional.HashSecretArgon2id("token", testArgon2idParams)
+	assert.NilError(t, err)
+	assert.Assert(t, !other.Str.Match(hash))
+
+	parsed, err := optional.ParseHashedSecret(hash)
+	assert.NilError(t, err)
+	assert.Assert(t, parsed.Verify("token"))
+
+	_, err = optional.HashSecretArgon2id("token", optional.Argon2idParams{})
+	assert.Assert(t, err != nil)
+}
+
+func TestHashedSecretArgon2idLimits(t *testing.T) {
+	key := "$c2FsdHNhbHRzYWx0c2FsdA$a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+	cases := []string{
+		"$argon2id$v=19$m=4294967295,t=1,p=1" + key,
+		"$argon2id$v=19$m=64,t=4294967295,p=1" + key,
+		"$argon2id$v=19$m=64,t=1,p=255" + key,
+		"$argon2id$v=19$m=64,t=1,p=1$" + strings.Repeat("c2Fsd", 100) + "$a2V5a2V5",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$" + strings.Repeat("a2V5a", 1000),
+	}
+	for _, hash := range cases {
+		_, err := optional.ParseHashedSecret(hash)
+		assert.ErrorContains(t, err, "limit")
+
+		h := optional.NoHashedSecret()
+		assert.Assert(t, h.Set(hash) != nil)
+		assert.Assert(t, !h.Verify("token"))
+	}
+
+	params := testArgon2idParams
+	params.Memory = optional.MAX_ARGON2ID_MEMORY + 1
+	_, err := optional.HashSecretArgon2id("token", params)
+	assert.ErrorContains(t, err, "limit")
+}
+
+func TestHashedSecretMutationsValidate(t *testing.T) {
+	good, err := optional.HashSecretArgon2id("token", testArgon2idParams)
+	assert.NilError(t, err)
+	hash := good.MustGet()
+
+	h := optional.NoHashedSecret()
+	assert.Assert(t, h.Default(hash))
+	assert.Assert(t, h.Verify("token"))
+
+	// A rejected hash leaves the current one in place
+	err = h.Transform(func(string) (string, error) { return "plaintext", nil })
+	assert.Assert(t, err != nil)
+	assert.Assert(t, h.Verify("token"))
+	assert.Assert(t, h.Set("plaintext") != nil)
+	assert.Assert(t, h.Verify("token"))
+
+	// Replace can not return an error, but a malformed hash never verifies
+	prev := h.Replace("token")
+	assert.Assert(t, prev.Match(hash))
+	assert.Assert(t, !h.Verify("token"))
+}
+
+func TestHashedSecretBcryptCostLimit(t *testing.T) {
+	_, err := optional.HashSecretBcrypt("token", optional.MAX_BCRYPT_COST+1)
+	assert.ErrorContains(t, err, "limit")
+
+	// A cost 31 hash would take days to verify, so it is rejected without hashing anything
+	hash := "$2a$31$" + strings.Repeat("a", 53)
+	_, err = optional.ParseHashedSecret(hash)
+	assert.ErrorContains(t, err, "limit")
+
+	var h optional.HashedSecret
+	assert.Assert(t, h.UnmarshalText([]byte(hash)) != nil)
+	h.Replace(hash)
+	assert.Assert(t, !h.Verify("token"))
+}
+
+func TestHashedSecretBcrypt(t *testing.T) {
+	h, err := optional.HashSecretBcrypt("token", bcrypt.MinCost)
+	assert.NilError(t, err)
+	assert.Assert(t, h.Verify("token"))
+	assert.Assert(t, !h.Verify("wrong"))
+
+	hash, _ := h.Get()
+	parsed, err := optional.ParseHashedSecret(hash)
+	assert.NilError(t, err)
+	assert.Assert(t, parsed.Verify("token"))
+}
+
+func TestHashedSecretNone(t *testing.T) {
+	h := optional.NoHashedSecret()
+	assert.Assert(t, !h.Verify(""))
+	assert.Assert(t, !h.Verify("anything"))
+}
+
+func TestHashedSecretInvalid(t *testing.T) {
+	invalid := []string{
+		"plaintext token",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdA",
+		"$argon2id$v=18$m=64,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=64,t=0,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdA$!!!",
+		"$2a$04$tooshort",
+	}
+	for _, hash := range invalid {
+		_, err := optional.ParseHashedSecret(hash)
+		assert.Assert(t, err != nil, hash)
+
+		h := optional.NoHashedSecret()
+		assert.Assert(t, h.Set(hash) != nil, hash)
+		assert.Assert(t, h.Scan(hash) != nil, hash)
+		assert.Assert(t, h.IsNone())
+	}
+}
+
+func TestHashedSecretRedacted(t *testing.T) {
+	h, err := optional.HashSecretArgon2id("token", testArgon2idParams)
+	assert.NilError(t, err)
+	hash, _ := h.Get()
+
+	assert.Equal(t, "HashedSecret", h.Type())
+	assert.Equal(t, "***REDACTED***", h.String())
+	assert.Assert(t, !strings.Contains(fmt.Sprintf("%v %s %#v", h, h, h), hash))
+	assert.Equal(t, "***REDACTED***", h.LogValue().String())
+}
+
+func TestHashedSecretMarshaling(t *testing.T) {
+	h, err := optional.HashSecretArgon2id("token", testArgon2idParams)
+	assert.NilError(t, err)
+	hash, _ := h.Get()
+
+	type config struct {
+		Token optional.HashedSecret `json:"token"`
+	}
+
+	data, err := json.Marshal(config{Token: h})
+	assert.NilError(t, err)
+
+	var out config
+	assert.NilError(t, json.Unmarshal(data, &out))
+	assert.Assert(t, out.Token.Verify("token"))
+
+	assert.NilError(t, json.Unmarshal([]byte(`{"token":null}`), &out))
+	assert.Assert(t, out.Token.IsNone())
+	assert.Assert(t, json.Unmarshal([]byte(`{"token":"plaintext"}`), &out) != nil)
+
+	text, err := h.MarshalText()
+	assert.NilError(t, err)
+	assert.Equal(t, hash, string(text))
+
+	val, err := h.Value()
+	assert.NilError(t, err)
+	assert.Equal(t, hash, val)
+
+	var scanned optional.HashedSecret
+	assert.NilError(t, scanned.Scan([]byte(hash)))
+	assert.Assert(t, scanned.Verify("token"))
+	assert.NilError(t, scanned.Scan(nil))
+	assert.Assert(t, scanned.IsNone())
+}
@@ -3,7 +3,6 @@ package optional
 import (
 	"database/sql/driver"
 	"fmt"
-	"math"
 	"strconv"
 )
 
@@ -68,6 +67,31 @@ func (o *Int) UnmarshalText(text []byte) error {
 	return nil
 }
 
+// Scan implements database/sql.Scanner interface. int64, float64, bool, string, and []byte values are all accepted
+// as long as the value fits into int.
+func (o *Int) Scan(src any) error {
+	if src == nil {
+		// NULL value row
+		o.Clear()
+		return nil
+	}
+	val, err := scanNumber[int](src)
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
+	}
+	_ = o.Replace(val)
+	return nil
+}
+
+// Value implements the database/sql/driver.Valuer interface
+func (o Int) Value() (driver.Value, error) {
+	val, ok := o.Get()
+	if ok {
+		return int64(val), nil
+	}
+	return nil, nil
+}
+
 func (o Int) Add(i Int) Int {
 	a, ok := o.Get()
 	if !ok {
@@ -81,6 +105,12 @@ func (o Int) Add(i Int) Int {
 	return SomeInt(a + b)
 }
 
+// Cmp compares o with other the same as Compare, with None sorting first. It can be passed straight to slices.SortFunc
+// as Int.Cmp.
+func (o Int) Cmp(other Int) int {
+	return Compare(o.Option, other.Option, true)
+}
+
 // 8bit sized int
 
 type Int8 struct {
@@ -142,24 +172,19 @@ func (o *Int8) UnmarshalText(text []byte) error {
 	return nil
 }
 
-// Scan implements database/sql.Scanner interface.
+// Scan implements database/sql.Scanner interface. int64, float64, bool, string, and []byte values are all accepted
+// as long as the value fits into int8.
 func (o *Int8) Scan(src any) error {
 	if src == nil {
 		// NULL value row
 		o.Clear()
 		return nil
 	}
-	switch t := src.(type) {
-	case int64:
-		if t > math.MaxInt8 || t < 0 {
-			return fmt.Errorf("int64 of value %d cannot fit in int8", t)
-		}
-		_ = o.Replace(int8(t))
-	case int8:
-		_ = o.Replace(t)
-	default:
-		return fmt.Errorf("converting driver.Value type %T to %s", src, o.Type())
+	val, err := scanNumber[int8](src)
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
 	}
+	_ = o.Replace(val)
 	return nil
 }
 
@@ -185,6 +210,12 @@ func (o Int8) Add(i Int8) Int8 {
 	return SomeInt8(a + b)
 }
 
+// Cmp compares o with other the same as Compare, with None sorting first. It can be passed straight to slices.SortFunc
+// as Int8.Cmp.
+func (o Int8) Cmp(other Int8) int {
+	return Compare(o.Option, other.Option, true)
+}
+
 // 16bit sized int
 
 type Int16 struct {
@@ -246,24 +277,19 @@ func (o *Int16) UnmarshalText(text []byte) error {
 	return nil
 }
 
-// Scan implements database/sql.Scanner interface.
+// Scan implements database/sql.Scanner interface. int64, float64, bool, string, and []byte values are all accepted
+// as long as the value fits into int16.
 func (o *Int16) Scan(src any) error {
 	if src == nil {
 		// NULL value row
 		o.Clear()
 		return nil
 	}
-	switch t := src.(type) {
-	case int64:
-		if t > math.MaxInt16 || t < 0 {
-			return fmt.Errorf("int64 of value %d cannot fit in int16", t)
-		}
-		_ = o.Replace(int16(t))
-	case int16:
-		_ = o.Replace(t)
-	default:
-		return fmt.Errorf("converting driver.Value type %T to %s", src, o.Type())
+	val, err := scanNumber[int16](src)
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
 	}
+	_ = o.Replace(val)
 	return nil
 }
 
@@ -289,6 +315,12 @@ func (o Int16) Add(i Int16) Int16 {
 	return SomeInt16(a + b)
 }
 
+// Cmp compares o with other the same as Compare, with None sorting first. It can be passed straight to slices.SortFunc
+// as Int16.Cmp.
+func (o Int16) Cmp(other Int16) int {
+	return Compare(o.Option, other.Option, true)
+}
+
 // 32bit sized int
 
 type Int32 struct {
@@ -350,24 +382,19 @@ func (o *Int32) UnmarshalText(text []byte) error {
 	return nil
 }
 
-// Scan implements database/sql.Scanner interface.
+// Scan implements database/sql.Scanner interface. int64, float64, bool, string, and []byte values are all accepted
+// as long as the value fits into int32.
 func (o *Int32) Scan(src any) error {
 	if src == nil {
 		// NULL value row
 		o.Clear()
 		return nil
 	}
-	switch t := src.(type) {
-	case int64:
-		if t > math.MaxInt32 || t < 0 {
-			return fmt.Errorf("int64 of value %d cannot fit in int32", t)
-		}
-		_ = o.Replace(int32(t))
-	case int32:
-		_ = o.Replace(t)
-	default:
-		return fmt.Errorf("converting driver.Value type %T to %s", src, o.Type())
+	val, err := scanNumber[int32](src)
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
 	}
+	_ = o.Replace(val)
 	return nil
 }
 
@@ -393,6 +420,12 @@ func (o Int32) Add(i Int32) Int32 {
 	return SomeInt32(a + b)
 }
 
+// Cmp compares o with other the same as Compare, with None sorting first. It can be passed straight to slices.SortFunc
+// as Int32.Cmp.
+func (o Int32) Cmp(other Int32) int {
+	return Compare(o.Option, other.Option, true)
+}
+
 // 64bit sized int
 
 type Int64 struct {
@@ -454,19 +487,19 @@ func (o *Int64) UnmarshalText(text []byte) error {
 	return nil
 }
 
-// Scan implements database/sql.Scanner interface.
+// Scan implements database/sql.Scanner interface. int64, float64, bool, string, and []byte values are all accepted
+// as long as the value fits into int64.
 func (o *Int64) Scan(src any) error {
 	if src == nil {
 		// NULL value row
 		o.Clear()
 		return nil
 	}
-	switch t := src.(type) {
-	case int64:
-		_ = o.Replace(t)
-	default:
-		return fmt.Errorf("converting driver.Value type %T to %s", src, o.Type())
+	val, err := scanNumber[int64](src)
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
 	}
+	_ = o.Replace(val)
 	return nil
 }
 
@@ -491,3 +524,9 @@ func (o Int64) Add(i Int64) Int64 {
 	}
 	return SomeInt64(a + b)
 }
+
+// Cmp compares o with other the same as Compare, with None sorting first. It can be passed straight to slices.SortFunc
+// as Int64.Cmp.
+func (o Int64) Cmp(other Int64) int {
+	return Compare(o.Option, other.Option, true)
+}
@@ -0,0 +1,95 @@
+// Package iter provides helpers for working with sequences and slices of optionals, built on the standard library's
+// range-over-func iterators. Every function accepts any optional.Optional, so the wrapper types such as optional.Int
+// work the same as optional.Option:
+//
+//	ints := []optional.Int{optional.SomeInt(1), optional.NoInt(), optional.SomeInt(3)}
+//	for i := range iter.Somes(slices.Values(ints)) {
+//		...
+//	}
+//
+// An optional.Option can not hold a slice, so results which are an optional slice are returned as an
+// optional.AnyOption instead.
+package iter
+
+import (
+	"iter"
+	"slices"
+
+	"github.com/brnsampson/optional"
+)
+
+// Somes yields the wrapped values of the Some elements of seq, skipping None elements.
+func Somes[O optional.Optional[T], T comparable](seq iter.Seq[O]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for o := range seq {
+			if val, ok := o.Get(); ok && !yield(val) {
+				return
+			}
+		}
+	}
+}
+
+// Collect returns the wrapped values of the Some elements of seq as a slice, skipping None elements.
+func Collect[O optional.Optional[T], T comparable](seq iter.Seq[O]) []T {
+	var vals []T
+	for val := range Somes(seq) {
+		vals = append(vals, val)
+	}
+	return vals
+}
+
+// FirstSome returns the first Some element of seq, or None if there isn't one. It stops reading seq as soon as a Some
+// element is found.
+func FirstSome[O optional.Optional[T], T comparable](seq iter.Seq[O]) optional.Option[T] {
+	for val := range Somes(seq) {
+		return optional.Some(val)
+	}
+	return optional.None[T]()
+}
+
+// AllSome returns Some holding the wrapped values of every element of seq if they are all Some. If any element is None
+// it returns None, without reading the rest of seq. An empty seq gives Some empty, non-nil slice.
+func AllSome[O optional.Optional[T], T comparable](seq iter.Seq[O]) optional.AnyOption[[]T] {
+	vals := []T{}
+	for o := range seq {
+		val, ok := o.Get()
+		if !ok {
+			return optional.NoneAny[[]T]()
+		}
+		vals = append(vals, val)
+	}
+	return optional.SomeAny(vals)
+}
+
+// Partition splits seq into the wrapped values of its Some elements and the positions of its None elements, which is
+// handy for reporting which rows of a batch were missing a value.
+func Partition[O optional.Optional[T], T comparable](seq iter.Seq[O]) (somes []T, nones []int) {
+	i := 0
+	for o := range seq {
+		if val, ok := o.Get(); ok {
+			somes = append(somes, val)
+		} else {
+			nones = append(nones, i)
+		}
+		i++
+	}
+	return somes, nones
+}
+
+// Transpose turns a slice of optionals into an optional slice. It is the same as AllSome over the elements of opts.
+func Transpose[O optional.Optional[T], T comparable](opts []O) optional.AnyOption[[]T] {
+	return AllSome(slices.Values(opts))
+}
+
+// Distribute is the inverse of Transpose. It turns an optional slice into a slice of Some values, or nil if it is None.
+func Distribute[T comparable](opt optional.AnyOption[[]T]) []optional.Option[T] {
+	vals, ok := opt.Get()
+	if !ok {
+		return nil
+	}
+	opts := make([]optional.Option[T], len(vals))
+	for i, val := range vals {
+		opts[i] = optional.Some(val)
+	}
+	return opts
+}
@@ -0,0 +1,81 @@
+package iter_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/brnsampson/optional"
+	"github.com/brnsampson/optional/iter"
+	"gotest.tools/v3/assert"
+)
+
+func TestSomes(t *testing.T) {
+	ints := []optional.Int{optional.SomeInt(1), optional.NoInt(), optional.SomeInt(3)}
+	assert.DeepEqual(t, []int{1, 3}, slices.Collect(iter.Somes(slices.Values(ints))))
+	assert.DeepEqual(t, []int{1, 3}, iter.Collect(slices.Values(ints)))
+
+	// Stopping early stops reading the sequence
+	for val := range iter.Somes(slices.Values(ints)) {
+		assert.Equal(t, 1, val)
+		break
+	}
+
+	assert.Assert(t, iter.Collect(slices.Values([]optional.Int{optional.NoInt()})) == nil)
+}
+
+func TestFirstSome(t *testing.T) {
+	opts := []optional.Option[string]{optional.None[string](), optional.Some("b"), optional.Some("c")}
+	assert.Equal(t, "b", iter.FirstSome(slices.Values(opts)).MustGet())
+
+	read := 0
+	seq := func(yield func(optional.Option[string]) bool) {
+		for _, o := range opts {
+			read++
+			if !yield(o) {
+				return
+			}
+		}
+	}
+	iter.FirstSome(seq)
+	assert.Equal(t, 2, read, "FirstSome read past the first Some element")
+
+	assert.Assert(t, iter.FirstSome(slices.Values(opts[:1])).IsNone())
+}
+
+func TestAllSome(t *testing.T) {
+	all := iter.AllSome(slices.Values([]optional.Int{optional.SomeInt(1), optional.SomeInt(2)}))
+	assert.DeepEqual(t, []int{1, 2}, all.MustGet())
+
+	all = iter.AllSome(slices.Values([]optional.Int{optional.SomeInt(1), optional.NoInt()}))
+	assert.Assert(t, all.IsNone())
+
+	all = iter.AllSome(slices.Values([]optional.Int{}))
+	assert.Assert(t, all.IsSome())
+	assert.Assert(t, all.MustGet() != nil)
+	assert.Equal(t, 0, len(all.MustGet()))
+}
+
+func TestPartition(t *testing.T) {
+	floats := []optional.Float64{optional.NoFloat64(), optional.SomeFloat64(1.5), optional.NoFloat64(), optional.SomeFloat64(2)}
+	somes, nones := iter.Partition(slices.Values(floats))
+	assert.DeepEqual(t, []float64{1.5, 2}, somes)
+	assert.DeepEqual(t, []int{0, 2}, nones)
+}
+
+func TestTranspose(t *testing.T) {
+	vals := iter.Transpose([]optional.Str{optional.SomeStr("a"), optional.SomeStr("b")})
+	assert.DeepEqual(t, []string{"a", "b"}, vals.MustGet())
+
+	assert.Assert(t, iter.Transpose([]optional.Str{optional.SomeStr("a"), optional.NoStr()}).IsNone())
+
+	opts := iter.Distribute(vals)
+	assert.Equal(t, 2, len(opts))
+	assert.Assert(t, opts[0].Match("a"))
+	assert.Assert(t, opts[1].Match("b"))
+
+	// Round trip back again
+	back := iter.Transpose(opts)
+	assert.DeepEqual(t, vals.MustGet(), back.MustGet())
+
+	assert.Assert(t, iter.Distribute(optional.NoneAny[[]string]()) == nil)
+}
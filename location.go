@@ -0,0 +1,169 @@
+package optional
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Location is an optional *time.Location which is loaded from an IANA time zone name such as "America/New_York", as
+// well as "UTC" and "Local". Names are resolved with time.LoadLocation, so the zone database must be available, e.g.
+// by importing time/tzdata.
+type Location struct {
+	Option[*time.Location]
+}
+
+func SomeLocation(value *time.Location) Location {
+	return Location{Some(value)}
+}
+
+func NoLocation() Location {
+	return Location{None[*time.Location]()}
+}
+
+// ParseLocation loads the location with the given IANA name.
+func ParseLocation(name string) (Location, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return NoLocation(), err
+	}
+	return SomeLocation(loc), nil
+}
+
+func (o Location) Type() string {
+	return "Location"
+}
+
+func (o *Location) Set(str string) error {
+	return o.UnmarshalText([]byte(str))
+}
+
+// Match compares locations by name, since loading the same zone twice gives two different pointers.
+func (o Location) Match(probe *time.Location) bool {
+	val, ok := o.Get()
+	return ok && val != nil && probe != nil && val.String() == probe.String()
+}
+
+// GetOrUTC returns the location, or time.UTC if it is None. This is convenient for passing to Time.WithLocation or
+// time.Time.In.
+func (o Location) GetOrUTC() *time.Location {
+	val, ok := o.Get()
+	if !ok || val == nil {
+		return time.UTC
+	}
+	return val
+}
+
+func (o Location) String() string {
+	if o.IsNone() {
+		return "None[Location]"
+	} else {
+		tmp, ok := o.Get()
+		if !ok || tmp == nil {
+			return "Error[Location]"
+		}
+		return tmp.String()
+	}
+}
+
+func (o Location) MarshalText() (text []byte, err error) {
+	if o.IsNone() {
+		return []byte("None"), nil
+	} else {
+		tmp, ok := o.Get()
+		if !ok || tmp == nil {
+			return nil, optionalError("Attempted to Get Option with None value")
+		}
+		return []byte(tmp.String()), nil
+	}
+}
+
+func (o *Location) UnmarshalText(text []byte) error {
+	tmp := string(text)
+	if isNoneString(tmp) {
+		o.Clear()
+		return nil
+	}
+
+	loc, err := time.LoadLocation(tmp)
+	if err != nil {
+		return err
+	}
+	o.Replace(loc)
+	return nil
+}
+
+// MarshalJSON writes the location's name as a json string.
+func (o Location) MarshalJSON() ([]byte, error) {
+	if o.IsNone() {
+		return json.Marshal(nil)
+	}
+	tmp, err := o.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(tmp))
+}
+
+// UnmarshalJSON implements encoding/json.Unmarshaller interface
+func (o *Location) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		o.Clear()
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return o.UnmarshalText([]byte(s))
+}
+
+// MarshalTOML implements the toml.Marshaler interface.
+func (o Location) MarshalTOML() ([]byte, error) {
+	tmp, err := o.MarshalText()
+	return []byte(tomlQuote(string(tmp))), err
+}
+
+// UnmarshalTOML implements the toml.Unmarshaler interface.
+func (o *Location) UnmarshalTOML(data any) error {
+	s, ok := data.(string)
+	if !ok {
+		return fmt.Errorf("converting TOML type %T to %s", data, o.Type())
+	}
+	return o.UnmarshalText([]byte(s))
+}
+
+// Scan implements database/sql.Scanner interface. Locations are stored by name.
+func (o *Location) Scan(src any) error {
+	if src == nil {
+		// NULL value row
+		o.Clear()
+		return nil
+	}
+
+	var name string
+	switch t := src.(type) {
+	case string:
+		name = t
+	case []byte:
+		name = string(t)
+	default:
+		return fmt.Errorf("converting driver.Value type %T to %s", src, o.Type())
+	}
+
+	if err := o.UnmarshalText([]byte(name)); err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
+	}
+	return nil
+}
+
+// Value implements the database/sql/driver.Valuer interface
+func (o Location) Value() (driver.Value, error) {
+	val, ok := o.Get()
+	if ok && val != nil {
+		return val.String(), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,100 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/brnsampson/optional"
+	"go-simpler.org/env"
+	"gotest.tools/v3/assert"
+)
+
+func TestLocationType(t *testing.T) {
+	o := optional.NoLocation()
+	assert.Equal(t, "Location", o.Type())
+	assert.Equal(t, "None[Location]", o.String())
+	assert.Equal(t, time.UTC, o.GetOrUTC())
+}
+
+func TestLocationParse(t *testing.T) {
+	o, err := optional.ParseLocation("America/New_York")
+	assert.NilError(t, err)
+	assert.Equal(t, "America/New_York", o.String())
+
+	ny, err := time.LoadLocation("America/New_York")
+	assert.NilError(t, err)
+	assert.Assert(t, o.Match(ny))
+	assert.Assert(t, !o.Match(time.UTC))
+	assert.Equal(t, o.MustGet(), o.GetOrUTC())
+
+	_, err = optional.ParseLocation("Not/AZone")
+	assert.Assert(t, err != nil)
+
+	assert.NilError(t, o.Set("UTC"))
+	assert.Assert(t, o.Match(time.UTC))
+	assert.NilError(t, o.Set("None"))
+	assert.Assert(t, o.IsNone())
+	assert.Assert(t, o.Set("Not/AZone") != nil)
+}
+
+func TestLocationJSON(t *testing.T) {
+	type config struct {
+		Zone optional.Location `json:"zone"`
+	}
+
+	var cfg config
+	assert.NilError(t, json.Unmarshal([]byte(`{"zone":"Europe/Paris"}`), &cfg))
+	assert.Equal(t, "Europe/Paris", cfg.Zone.String())
+
+	data, err := json.Marshal(cfg)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"zone":"Europe/Paris"}`, string(data))
+
+	assert.NilError(t, json.Unmarshal([]byte(`{"zone":null}`), &cfg))
+	assert.Assert(t, cfg.Zone.IsNone())
+
+	data, err = json.Marshal(cfg)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"zone":null}`, string(data))
+
+	assert.Assert(t, json.Unmarshal([]byte(`{"zone":"Not/AZone"}`), &cfg) != nil)
+}
+
+func TestLocationFlagsAndEnv(t *testing.T) {
+	var cfg struct {
+		Zone  optional.Location `flag:"zone" env:"ZONE"`
+		Other optional.Location `flag:"other" env:"OTHER"`
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	assert.NilError(t, optional.BindFlags(fs, &cfg))
+	assert.NilError(t, fs.Parse([]string{"-zone", "Asia/Tokyo"}))
+	assert.Equal(t, "Asia/Tokyo", cfg.Zone.String())
+
+	assert.NilError(t, optional.LoadEnv(&cfg, optional.WithEnvSource(env.Map{"OTHER": "Europe/London"})))
+	assert.Equal(t, "Europe/London", cfg.Other.String())
+}
+
+func TestLocationSql(t *testing.T) {
+	o, err := optional.ParseLocation("Australia/Sydney")
+	assert.NilError(t, err)
+
+	val, err := o.Value()
+	assert.NilError(t, err)
+	assert.Equal(t, "Australia/Sydney", val)
+
+	var out optional.Location
+	assert.NilError(t, out.Scan([]byte("Australia/Sydney")))
+	assert.Equal(t, "Australia/Sydney", out.String())
+
+	assert.NilError(t, out.Scan(nil))
+	assert.Assert(t, out.IsNone())
+	val, err = out.Value()
+	assert.NilError(t, err)
+	assert.Assert(t, val == nil)
+
+	assert.Assert(t, out.Scan(int64(1)) != nil)
+	assert.Assert(t, out.Scan("Not/AZone") != nil)
+}
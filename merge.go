@@ -0,0 +1,77 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// someable is satisfied by Option and every type which embeds one. Merge uses it to find the optional fields of a
+// struct without needing to know the type parameter.
+type someable interface {
+	IsSome() bool
+}
+
+// Merge walks the fields of the struct pointed to by dst and, for every field which holds an Option or a type wrapping
+// an Option (Int, Str, Time, Secret, etc.), keeps the first Some value found in precedence order. dst itself has the
+// highest precedence, followed by each of srcs in the order given. Fields which are plain structs are merged
+// recursively. Fields which are pointers to an optional are treated as None when nil, and the pointer itself is copied.
+// Any other fields, as well as unexported fields, are left untouched in dst.
+//
+// Each src must either be a value of the same struct type as dst or a pointer to one. nil sources are skipped. This is
+// the same as calling Or on every optional field by hand, which makes layered configuration simple:
+//
+// var conf Config
+// err := optional.Merge(&conf, flagConf, fileConf, envConf, defaultConf)
+func Merge(dst any, srcs ...any) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Pointer || dv.IsNil() || dv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("merge destination must be a non-nil pointer to a struct, got %T", dst)
+	}
+	dv = dv.Elem()
+
+	for i, src := range srcs {
+		sv := reflect.ValueOf(src)
+		if !sv.IsValid() {
+			continue
+		}
+		if sv.Kind() == reflect.Pointer {
+			if sv.IsNil() {
+				continue
+			}
+			sv = sv.Elem()
+		}
+
+		if sv.Type() != dv.Type() {
+			return fmt.Errorf("merge source %d has type %s, expected %s", i, sv.Type(), dv.Type())
+		}
+		mergeStruct(dv, sv)
+	}
+	return nil
+}
+
+// isSomeField reports if v holds an optional with a value. A nil pointer to an optional is None.
+func isSomeField(v reflect.Value) bool {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return false
+	}
+	opt, ok := v.Interface().(someable)
+	return ok && opt.IsSome()
+}
+
+func mergeStruct(dst, src reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		if !dst.Type().Field(i).IsExported() {
+			continue
+		}
+
+		df := dst.Field(i)
+		sf := src.Field(i)
+		if _, ok := df.Interface().(someable); ok {
+			if !isSomeField(df) && isSomeField(sf) {
+				df.Set(sf)
+			}
+		} else if df.Kind() == reflect.Struct {
+			mergeStruct(df, sf)
+		}
+	}
+}
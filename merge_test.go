@@ -0,0 +1,101 @@
+package optional_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brnsampson/optional"
+	"gotest.tools/v3/assert"
+)
+
+type mergeTestTLS struct {
+	Enabled optional.Bool
+	Cert    optional.Str
+}
+
+type mergeTestConfig struct {
+	Host     optional.Str
+	Port     optional.Int
+	Ratio    optional.Option[float64]
+	Timeout  optional.Duration
+	Started  optional.Time
+	Password optional.Secret
+	TLS      mergeTestTLS
+	Name     string
+	hidden   optional.Int
+}
+
+func TestMergePrecedence(t *testing.T) {
+	now := time.Now()
+	flags := mergeTestConfig{
+		Port: optional.SomeInt(8080),
+		TLS:  mergeTestTLS{Enabled: optional.SomeBool(false)},
+	}
+	file := &mergeTestConfig{
+		Host:     optional.SomeStr("example.com"),
+		Port:     optional.SomeInt(1443),
+		Password: optional.SomeSecret("hunter2"),
+		TLS:      mergeTestTLS{Enabled: optional.SomeBool(true), Cert: optional.SomeStr("/etc/cert.pem")},
+		Name:     "ignored",
+		hidden:   optional.SomeInt(1),
+	}
+	env := mergeTestConfig{
+		Host:    optional.SomeStr("localhost"),
+		Ratio:   optional.Some(0.5),
+		Timeout: optional.SomeDuration(time.Second),
+		Started: optional.SomeTime(now),
+	}
+
+	var conf mergeTestConfig
+	err := optional.Merge(&conf, flags, file, nil, env)
+	assert.NilError(t, err)
+
+	assert.Assert(t, conf.Host.Match("example.com"))
+	assert.Assert(t, conf.Port.Match(8080))
+	assert.Assert(t, conf.Ratio.Match(0.5))
+	assert.Assert(t, conf.Timeout.Match(time.Second))
+	assert.Assert(t, conf.Started.Match(now))
+	assert.Assert(t, conf.Password.Match("hunter2"))
+	assert.Assert(t, conf.TLS.Enabled.Match(false))
+	assert.Assert(t, conf.TLS.Cert.Match("/etc/cert.pem"))
+
+	// Non-optional and unexported fields are left alone
+	assert.Equal(t, "", conf.Name)
+	assert.Assert(t, conf.hidden.IsNone())
+}
+
+func TestMergeKeepsDestination(t *testing.T) {
+	conf := mergeTestConfig{Port: optional.SomeInt(22)}
+	err := optional.Merge(&conf, mergeTestConfig{Port: optional.SomeInt(8080), Host: optional.SomeStr("localhost")})
+	assert.NilError(t, err)
+	assert.Assert(t, conf.Port.Match(22))
+	assert.Assert(t, conf.Host.Match("localhost"))
+}
+
+func TestMergeErrors(t *testing.T) {
+	var conf mergeTestConfig
+	err := optional.Merge(conf)
+	assert.Assert(t, err != nil)
+
+	err = optional.Merge(&conf, mergeTestTLS{})
+	assert.Assert(t, err != nil)
+}
+
+func TestMergePointerFields(t *testing.T) {
+	type config struct {
+		P *optional.Int
+		Q *optional.Int
+	}
+
+	one, two, none := optional.SomeInt(1), optional.SomeInt(2), optional.NoInt()
+	conf := config{Q: &none}
+	assert.NilError(t, optional.Merge(&conf, config{P: &one}, config{P: &two, Q: &two}))
+	assert.Assert(t, conf.P == &one)
+	assert.Assert(t, conf.Q == &two)
+
+	// nil pointers never replace a value
+	conf = config{}
+	assert.NilError(t, optional.Merge(&conf, config{}, config{P: &none}))
+	assert.Assert(t, conf.P == nil)
+	assert.Assert(t, conf.Q == nil)
+}
@@ -0,0 +1,224 @@
+package optional
+
+import (
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+type nullableState uint8
+
+const (
+	absent nullableState = iota
+	null
+	present
+)
+
+// Nullable is a tri-state optional which can tell the difference between a value which was never given (Absent), a
+// value which was explicitly cleared (Null), and an actual value. This is most useful for JSON PATCH style APIs, where
+// a missing key means "don't touch this field" while a null means "clear this field". An Option can't tell those apart
+// since both end up as None.
+//
+// The zero value of a Nullable is Absent, so any key missing from a JSON document leaves the field Absent while a json
+// null unmarshals to Null.
+type Nullable[T comparable] struct {
+	inner T
+	state nullableState
+}
+
+// Absent returns a Nullable which was never given a value.
+func Absent[T comparable]() Nullable[T] {
+	return Nullable[T]{}
+}
+
+// Null returns a Nullable which was explicitly set to null.
+func Null[T comparable]() Nullable[T] {
+	return Nullable[T]{state: null}
+}
+
+// Present returns a Nullable holding value.
+func Present[T comparable](value T) Nullable[T] {
+	return Nullable[T]{inner: value, state: present}
+}
+
+func (n Nullable[T]) IsAbsent() bool {
+	return n.state == absent
+}
+
+func (n Nullable[T]) IsNull() bool {
+	return n.state == null
+}
+
+// IsPresent returns true if the Nullable holds a value, i.e. it is neither Absent nor Null.
+func (n Nullable[T]) IsPresent() bool {
+	return n.state == present
+}
+
+// IsZero returns true only for Absent values, so that fields tagged with `json:",omitzero"` are dropped when they were
+// never given while explicit nulls are still emitted.
+func (n Nullable[T]) IsZero() bool {
+	return n.IsAbsent()
+}
+
+// Get returns the wrapped value and true if the Nullable is present. Note that the value returned if ok == false is
+// undefined so ALWAYS CHECK.
+func (n Nullable[T]) Get() (val T, ok bool) {
+	return n.inner, n.IsPresent()
+}
+
+func (n Nullable[T]) storedValue() (any, bool) {
+	return n.inner, n.IsPresent()
+}
+
+// MustGet is exactly like Get, but panics if the Nullable is Absent or Null.
+func (n Nullable[T]) MustGet() T {
+	if !n.IsPresent() {
+		panic("Attempted to call MustGet on a Nullable without a value")
+	}
+	return n.inner
+}
+
+// Match tests if the Nullable is present and the inner value == the passed value.
+func (n Nullable[T]) Match(probe T) bool {
+	return n.IsPresent() && n.inner == probe
+}
+
+// Option converts the Nullable into an Option. Present values become Some, while both Absent and Null become None.
+func (n Nullable[T]) Option() Option[T] {
+	if n.IsPresent() {
+		return Some(n.inner)
+	}
+	return None[T]()
+}
+
+// ApplyTo applies the Nullable to opt with RFC 7396 JSON merge patch semantics: Absent leaves opt untouched, Null
+// clears it, and a present value replaces it.
+func (n Nullable[T]) ApplyTo(opt *Option[T]) {
+	switch n.state {
+	case null:
+		opt.Clear()
+	case present:
+		opt.Replace(n.inner)
+	}
+}
+
+func (n Nullable[T]) String() string {
+	switch n.state {
+	case absent:
+		return "Absent"
+	case null:
+		return "Null"
+	default:
+		text, err := n.MarshalText()
+		if err != nil {
+			return "Error[Nullable]"
+		}
+		return string(text)
+	}
+}
+
+// MarshalJSON implements the encoding.json.Marshaler interface. Present values are passed into json.Marshal directly,
+// while both Null and Absent values are marshaled to json null.
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	if !n.IsPresent() {
+		return json.Marshal(nil)
+	}
+	return json.Marshal(n.inner)
+}
+
+// UnmarshalJSON implements the encoding.json.Unmarshaller interface. Json nulls are unmarshaled into Null, while any
+// other value is unmarshaled as normal. Keys which are missing from the document never call UnmarshalJSON, which is
+// what leaves those fields Absent.
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*n = Null[T]()
+		return nil
+	}
+
+	var tmp T
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+	*n = Present(tmp)
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. Null and Absent values are both marshaled to "null",
+// since there is no way to represent a missing value in text.
+func (n Nullable[T]) MarshalText() (text []byte, err error) {
+	if !n.IsPresent() {
+		return []byte("null"), nil
+	}
+
+	switch t := any(n.inner).(type) {
+	case time.Time:
+		return []byte(t.Format(DEFAULT_TIME_FORMAT)), nil
+	case encoding.TextMarshaler:
+		return t.MarshalText()
+	}
+
+	rv := reflect.ValueOf(n.inner)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return []byte(strconv.FormatInt(rv.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return []byte(strconv.FormatUint(rv.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		return []byte(strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits())), nil
+	case reflect.Bool:
+		return []byte(strconv.FormatBool(rv.Bool())), nil
+	case reflect.String:
+		return []byte(rv.String()), nil
+	default:
+		return nil, fmt.Errorf("cannot marshal type %T into text", n.inner)
+	}
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. The strings "None", "none", "null", and "nil" are
+// unmarshaled into Null. Anything else is parsed into T.
+func (n *Nullable[T]) UnmarshalText(text []byte) error {
+	tmp := string(text)
+	if isNoneString(tmp) {
+		*n = Null[T]()
+		return nil
+	}
+
+	var val T
+	if err := unmarshalNative(tmp, &val); err != nil {
+		return err
+	}
+	*n = Present(val)
+	return nil
+}
+
+// Scan implements database/sql.Scanner interface. NULL values are scanned into Null.
+func (n *Nullable[T]) Scan(src any) error {
+	if src == nil {
+		// NULL value row
+		*n = Null[T]()
+		return nil
+	}
+
+	if b, ok := src.([]byte); ok {
+		src = string(b)
+	}
+
+	var val T
+	if err := unmarshalNative(src, &val); err != nil {
+		return fmt.Errorf("converting driver.Value type %T to Nullable[%T]: %w", src, val, err)
+	}
+	*n = Present(val)
+	return nil
+}
+
+// Value implements the database/sql/driver.Valuer interface. Both Null and Absent values are stored as NULL.
+func (n Nullable[T]) Value() (driver.Value, error) {
+	if !n.IsPresent() {
+		return nil, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(n.inner)
+}
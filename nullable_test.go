@@ -0,0 +1,142 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/brnsampson/optional"
+	"gotest.tools/v3/assert"
+)
+
+func TestNullableZeroValueIsAbsent(t *testing.T) {
+	var n optional.Nullable[int]
+	assert.Assert(t, n.IsAbsent())
+	assert.Assert(t, !n.IsNull())
+	assert.Assert(t, !n.IsPresent())
+	assert.Equal(t, "Absent", n.String())
+}
+
+func TestNullableBasics(t *testing.T) {
+	n := optional.Present(42)
+	assert.Assert(t, n.IsPresent())
+	assert.Assert(t, n.Match(42))
+	assert.Equal(t, 42, n.MustGet())
+	assert.Assert(t, n.Option().Match(42))
+	assert.Equal(t, "42", n.String())
+
+	null := optional.Null[int]()
+	assert.Assert(t, null.IsNull())
+	assert.Assert(t, !null.Match(0))
+	assert.Assert(t, null.Option().IsNone())
+	_, ok := null.Get()
+	assert.Assert(t, !ok)
+}
+
+func TestNullableUnmarshalJson(t *testing.T) {
+	type patch struct {
+		Name optional.Nullable[string] `json:"name"`
+		Port optional.Nullable[int]    `json:"port"`
+		Host optional.Nullable[string] `json:"host"`
+	}
+
+	var p patch
+	err := json.Unmarshal([]byte(`{"name": null, "port": 8080}`), &p)
+	assert.NilError(t, err)
+	assert.Assert(t, p.Name.IsNull())
+	assert.Assert(t, p.Port.Match(8080))
+	assert.Assert(t, p.Host.IsAbsent())
+
+	err = json.Unmarshal([]byte(`{"port": "not a number"}`), &p)
+	assert.Assert(t, err != nil)
+}
+
+func TestNullableMarshalJson(t *testing.T) {
+	res, err := json.Marshal(optional.Present("hi"))
+	assert.NilError(t, err)
+	assert.Equal(t, `"hi"`, string(res))
+
+	res, err = json.Marshal(optional.Null[string]())
+	assert.NilError(t, err)
+	assert.Equal(t, `null`, string(res))
+}
+
+func TestNullableText(t *testing.T) {
+	var n optional.Nullable[int16]
+	err := n.UnmarshalText([]byte("1234"))
+	assert.NilError(t, err)
+	assert.Assert(t, n.Match(1234))
+
+	s, err := n.MarshalText()
+	assert.NilError(t, err)
+	assert.Equal(t, "1234", string(s))
+
+	err = n.UnmarshalText([]byte("null"))
+	assert.NilError(t, err)
+	assert.Assert(t, n.IsNull())
+
+	err = n.UnmarshalText([]byte("99999"))
+	assert.Assert(t, err != nil)
+}
+
+func TestNullableApplyTo(t *testing.T) {
+	opt := optional.Some(1)
+	optional.Absent[int]().ApplyTo(&opt)
+	assert.Assert(t, opt.Match(1))
+
+	optional.Present(2).ApplyTo(&opt)
+	assert.Assert(t, opt.Match(2))
+
+	optional.Null[int]().ApplyTo(&opt)
+	assert.Assert(t, opt.IsNone())
+
+	// Works with the embedded Option of the wrapper types too
+	port := optional.SomeInt(8080)
+	optional.Present(22).ApplyTo(&port.Option)
+	assert.Assert(t, port.Match(22))
+}
+
+func TestNullableSql(t *testing.T) {
+	ins := "INSERT INTO optionTest (val) VALUES (?)"
+	q := `SELECT * FROM optionTest WHERE val = ?`
+	test1 := optional.Present[int32](42)
+	test2 := optional.Null[int32]()
+	out1 := optional.Absent[int32]()
+	out2 := optional.Present[int32](1)
+
+	db, mock, err := sqlmock.New()
+	assert.NilError(t, err, "failed to open mock database connection")
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO optionTest").WithArgs(int64(42)).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO optionTest").WithArgs(nil).WillReturnResult(sqlmock.NewResult(2, 1))
+	r1 := sqlmock.NewRows([]string{"val"})
+	r1.AddRow(int64(42))
+	mock.ExpectQuery(`SELECT (.+) FROM optionTest`).WithArgs(int64(42)).WillReturnRows(r1)
+	r2 := sqlmock.NewRows([]string{"val"})
+	r2.AddRow(nil)
+	mock.ExpectQuery(`SELECT (.+) FROM optionTest`).WithArgs(nil).WillReturnRows(r2)
+
+	_, err = db.Exec(ins, test1)
+	assert.NilError(t, err)
+	_, err = db.Exec(ins, test2)
+	assert.NilError(t, err)
+
+	rows1, err := db.Query(q, test1)
+	assert.NilError(t, err)
+	defer rows1.Close()
+	for rows1.Next() {
+		err = rows1.Scan(&out1)
+		assert.NilError(t, err)
+		assert.Assert(t, out1.Match(42))
+	}
+
+	rows2, err := db.Query(q, test2)
+	assert.NilError(t, err)
+	defer rows2.Close()
+	for rows2.Next() {
+		err = rows2.Scan(&out2)
+		assert.NilError(t, err)
+		assert.Assert(t, out2.IsNull())
+	}
+}
@@ -0,0 +1,79 @@
+//go:build go1.24
+
+// encoding/json only supports the omitzero tag from go 1.24, so these tests are skipped on older releases.
+
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/brnsampson/optional"
+	"gotest.tools/v3/assert"
+)
+
+func TestOptionOmitZeroJson(t *testing.T) {
+	type omitted struct {
+		Opt      optional.Option[string] `json:"opt,omitzero"`
+		Int      optional.Int            `json:"int,omitzero"`
+		Int8     optional.Int8           `json:"int8,omitzero"`
+		Int16    optional.Int16          `json:"int16,omitzero"`
+		Int32    optional.Int32          `json:"int32,omitzero"`
+		Int64    optional.Int64          `json:"int64,omitzero"`
+		Uint     optional.Uint           `json:"uint,omitzero"`
+		Uint8    optional.Uint8          `json:"uint8,omitzero"`
+		Uint16   optional.Uint16         `json:"uint16,omitzero"`
+		Uint32   optional.Uint32         `json:"uint32,omitzero"`
+		Uint64   optional.Uint64         `json:"uint64,omitzero"`
+		Float32  optional.Float32        `json:"float32,omitzero"`
+		Float64  optional.Float64        `json:"float64,omitzero"`
+		Str      optional.Str            `json:"str,omitzero"`
+		Secret   optional.Secret         `json:"secret,omitzero"`
+		Time     optional.Time           `json:"time,omitzero"`
+		Duration optional.Duration       `json:"duration,omitzero"`
+		Byte     optional.Byte           `json:"byte,omitzero"`
+		Bool     optional.Bool           `json:"bool,omitzero"`
+		Nullable optional.Nullable[int]  `json:"nullable,omitzero"`
+	}
+
+	res, err := json.Marshal(omitted{})
+	assert.NilError(t, err)
+	assert.Equal(t, `{}`, string(res))
+
+	res, err = json.Marshal(omitted{Str: optional.SomeStr(""), Bool: optional.SomeBool(false), Nullable: optional.Null[int]()})
+	assert.NilError(t, err)
+	assert.Equal(t, `{"str":"","bool":false,"nullable":null}`, string(res))
+}
+
+type omitZeroRetries struct{ optional.Int16 }
+
+func (r omitZeroRetries) IsZero() bool { return r.IsNone() || r.Match(0) }
+
+func TestOmitZeroJson(t *testing.T) {
+	type omitted struct {
+		A optional.OmitZero[int16]  `json:"a,omitzero"`
+		B omitZeroRetries           `json:"b,omitzero"`
+		C optional.Option[int16]    `json:"c,omitzero"`
+		D optional.OmitZero[string] `json:"d,omitzero"`
+	}
+
+	c := omitted{
+		A: optional.OmitZero[int16]{optional.Some[int16](0)},
+		B: omitZeroRetries{optional.SomeInt16(0)},
+		C: optional.Some[int16](0),
+		D: optional.OmitZero[string]{optional.Some("")},
+	}
+	res, err := json.Marshal(c)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"c":0}`, string(res))
+
+	// The choice belongs to the type, so it survives unmarshaling, Clone, and comparisons
+	var back omitted
+	assert.NilError(t, json.Unmarshal([]byte(`{"a":0,"b":0,"d":"x"}`), &back))
+	assert.Assert(t, back.A.IsZero())
+	assert.Assert(t, back.B.IsZero())
+	assert.Assert(t, back.A == c.A)
+	res, err = json.Marshal(back)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"d":"x"}`, string(res))
+}
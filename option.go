@@ -2,6 +2,7 @@ package optional
 
 import (
 	"encoding/json"
+	"iter"
 )
 
 // Option is a generic way to make a field or parameter optional. Instantiating an Optional value through the Some()
@@ -75,8 +76,11 @@ func (o Option[T]) MutableClone() MutableOptional[T] {
 	}
 }
 
-// Clear converts a Some(x) or None type Option into a None value.
+// Clear converts a Some(x) or None type Option into a None value. The old value is zeroed as well, so a None Option is
+// always the zero value of its type.
 func (o *Option[T]) Clear() {
+	var tmp T
+	o.inner = tmp
 	o.some = false
 }
 
@@ -116,6 +120,10 @@ func (o Option[T]) Get() (val T, ok bool) {
 	return o.inner, false
 }
 
+func (o Option[T]) storedValue() (any, bool) {
+	return o.inner, o.some
+}
+
 // MustGet is exactly like get, but panics for None instead of returning an error. This makes for potentially more
 // readable code if paired with Option.IsSome or in a template and gives an exciting sense of danger.
 func (o Option[T]) MustGet() T {
@@ -134,6 +142,20 @@ func (o Option[T]) Match(probe T) bool {
 	}
 }
 
+// All returns an iterator which yields the wrapped value once for Some values and nothing for None. This allows ranging
+// over an Option:
+//
+//	for val := range opt.All() {
+//		...
+//	}
+func (o Option[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if o.some {
+			yield(o.inner)
+		}
+	}
+}
+
 // Transform applies function f(T) to the inner value of the Option. If the Option is None, then the Option will remain
 // None.
 func (o *Option[T]) Transform(t Transformer[T]) error {
@@ -148,6 +170,37 @@ func (o *Option[T]) Transform(t Transformer[T]) error {
 	return nil
 }
 
+// IsZero returns true for None values. It is used by encoding/json in go 1.24 and later to drop fields tagged with
+// omitzero, so a struct full of None values no longer marshals to a wall of nulls. Some(zero value) is not considered
+// zero. Since every wrapper type embeds an Option, they all share this.
+//
+// To drop Some(zero value) as well, choose it through the type of the field: use OmitZero in place of Option, or for a
+// wrapper type such as Int, embed it in a type of your own which overrides IsZero:
+//
+//	type Retries struct{ optional.Int }
+//
+//	func (r Retries) IsZero() bool { return r.IsNone() || r.Match(0) }
+func (o Option[T]) IsZero() bool {
+	return o.IsNone()
+}
+
+// OmitZero is an Option whose IsZero is also true for Some(zero value), so that a field tagged with `json:",omitzero"`
+// is dropped for Some(0) or Some("") as well as for None. Since the choice is made by the type of the field, it is kept
+// through Clone, unmarshaling, and comparisons with ==.
+//
+//	type Config struct {
+//		Retries optional.OmitZero[int] `json:"retries,omitzero"`
+//	}
+//	conf := Config{Retries: optional.OmitZero[int]{optional.Some(0)}}
+type OmitZero[T comparable] struct {
+	Option[T]
+}
+
+func (o OmitZero[T]) IsZero() bool {
+	var zero T
+	return o.IsNone() || o.Match(zero)
+}
+
 // MarshalJSON implements the encoding.json.Marshaler interface. None values are marshaled to json null, while Some values are
 // passed into json.Marshal directly.
 func (o Option[T]) MarshalJSON() ([]byte, error) {
@@ -185,3 +238,33 @@ func (o *Option[T]) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+// MarshalTOML implements the toml.Marshaler interface. Some values are written as the native TOML type matching T,
+// so integers are written as TOML integers, time.Time as a TOML datetime, etc. TOML has no equivalent to null, so None
+// values are written as the string "None", which is unmarshaled back to None. To leave None values out of a document
+// entirely, tag the field with omitempty, e.g. `toml:"port,omitempty"`. A None Option is the zero value of its type,
+// which the toml encoder skips, while Some(0) is still written. EncodeTOML leaves out None values without the tag.
+func (o Option[T]) MarshalTOML() ([]byte, error) {
+	if o.IsNone() {
+		return []byte(`"None"`), nil
+	}
+	return marshalTOMLValue(o.inner)
+}
+
+// UnmarshalTOML implements the toml.Unmarshaler interface. Native TOML values are converted into T where that can be
+// done without losing information, and strings are parsed for numeric and boolean types. The strings "None", "none",
+// "null", and "nil" are unmarshaled into None values, the same as UnmarshalText on the wrapper types. Keys which are
+// missing from the document never call UnmarshalTOML, so the Option is left untouched.
+func (o *Option[T]) UnmarshalTOML(data any) error {
+	if s, ok := data.(string); ok && isNoneString(s) {
+		o.Clear()
+		return nil
+	}
+
+	var tmp T
+	if err := unmarshalNative(data, &tmp); err != nil {
+		return err
+	}
+	o.Replace(tmp)
+	return nil
+}
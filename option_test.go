@@ -378,3 +378,28 @@ func TestOptionUnMarshalJSON(t *testing.T) {
 	assert.NilError(t, err)
 	assert.Equal(t, expected, s)
 }
+
+func TestOptionIsZero(t *testing.T) {
+	assert.Assert(t, optional.None[int]().IsZero())
+	assert.Assert(t, !optional.Some(0).IsZero())
+	assert.Assert(t, !optional.Some(42).IsZero())
+
+	assert.Assert(t, optional.OmitZero[int]{optional.None[int]()}.IsZero())
+	assert.Assert(t, optional.OmitZero[int]{optional.Some(0)}.IsZero())
+	assert.Assert(t, !optional.OmitZero[int]{optional.Some(42)}.IsZero())
+}
+
+func TestOptionAll(t *testing.T) {
+	var vals []int
+	for val := range optional.Some(42).All() {
+		vals = append(vals, val)
+	}
+	assert.DeepEqual(t, []int{42}, vals)
+
+	for range optional.None[int]().All() {
+		t.Fatal("None yielded a value")
+	}
+
+	// Wrapper types get All through the embedded Option
+	assert.DeepEqual(t, []string{"a"}, slices.Collect(optional.SomeStr("a").All()))
+}
@@ -2,7 +2,10 @@
 // not have a designated value without using pointers and risking nil pointer dereferencing panics.
 package optional
 
-import "time"
+import (
+	"database/sql/driver"
+	"time"
+)
 
 type OptionalError struct {
 	msg string
@@ -59,8 +62,8 @@ type StorableOptional[T comparable] interface {
 
 	// Implements database/sql.Scanner interface
 	Scan(src any) error
-	// Implements the database/sqlc.Valuer interface
-	Value() (any, error)
+	// Implements the database/sql/driver.Valuer interface
+	Value() (driver.Value, error)
 }
 
 // LoadableOptional is an extension of the Optional interface meant to make it more useful for
@@ -133,11 +136,32 @@ func Or[T comparable, O Optional[T]](left, right O) O {
 	}
 }
 
+// AndAny is the same as And, except that it accepts any optional type with an IsSome method, including AnyOption,
+// Slice, and StrMap which can not implement Optional.
+func AndAny[O someable](left, right O) O {
+	if !left.IsSome() {
+		return left
+	} else {
+		return right
+	}
+}
+
+// OrAny is the same as Or, except that it accepts any optional type with an IsSome method, including AnyOption, Slice,
+// and StrMap which can not implement Optional.
+func OrAny[O someable](left, right O) O {
+	if left.IsSome() {
+		return left
+	} else {
+		return right
+	}
+}
+
 // ClearIfMatch calls clear if Optional.Match(probe) == true. This is a convenience for situations where you need to convert
 // from a value of T with known "magic value" which implies None. A good example of this is if you have an int loaded
 // from command line flags and you know that any flag omitted by the user will be assigned to 0. This can be done like this:
 // o := Some(x)
 // o.ClearIfMatch(0)
+// For flags specifically, BindFlags avoids the need for a magic value entirely.
 func ClearIfMatch[T comparable](opt MutableOptional[T], probe T) {
 	if opt.Match(probe) {
 		opt.Clear()
@@ -183,3 +207,78 @@ func TransformOr[T comparable](opt MutableOptional[T], t Transformer[T], backup
 	_ = opt.Default(backup)
 	return opt.Transform(t)
 }
+
+// Map applies f to the value of a Some Optional and returns the result as a new Option, which may be of a different
+// type. None is mapped to None. Unlike Transform, the Optional is not modified and f may change the type:
+//
+// port := optional.SomeStr("8080")
+// addr := optional.Map(port, func(p string) string { return ":" + p })
+func Map[T, U comparable](opt Optional[T], f func(T) U) Option[U] {
+	val, ok := opt.Get()
+	if !ok {
+		return None[U]()
+	}
+	return Some(f(val))
+}
+
+// MapErr is the same as Map, except f may fail. If f returns an error, the error is returned along with None.
+func MapErr[T, U comparable](opt Optional[T], f func(T) (U, error)) (Option[U], error) {
+	val, ok := opt.Get()
+	if !ok {
+		return None[U](), nil
+	}
+
+	res, err := f(val)
+	if err != nil {
+		return None[U](), err
+	}
+	return Some(res), nil
+}
+
+// FlatMap is the same as Map, except f returns an Option itself. This allows f to decide that some values should map
+// to None.
+func FlatMap[T, U comparable](opt Optional[T], f func(T) Option[U]) Option[U] {
+	val, ok := opt.Get()
+	if !ok {
+		return None[U]()
+	}
+	return f(val)
+}
+
+// Filter returns Some(x) if the Optional is Some(x) and f(x) == true, otherwise it returns None.
+func Filter[T comparable](opt Optional[T], f func(T) bool) Option[T] {
+	val, ok := opt.Get()
+	if !ok || !f(val) {
+		return None[T]()
+	}
+	return Some(val)
+}
+
+// Pair holds two values of possibly different types. It is the value type of the Option returned by Zip.
+type Pair[T, U comparable] struct {
+	First  T
+	Second U
+}
+
+// Zip combines two Optionals into a single Option of a Pair. The result is Some only if both left and right are Some.
+func Zip[T, U comparable](left Optional[T], right Optional[U]) Option[Pair[T, U]] {
+	l, ok := left.Get()
+	if !ok {
+		return None[Pair[T, U]]()
+	}
+
+	r, ok := right.Get()
+	if !ok {
+		return None[Pair[T, U]]()
+	}
+	return Some(Pair[T, U]{l, r})
+}
+
+// Unzip splits an Optional Pair back into two Options. None is split into two Nones.
+func Unzip[T, U comparable](opt Optional[Pair[T, U]]) (Option[T], Option[U]) {
+	p, ok := opt.Get()
+	if !ok {
+		return None[T](), None[U]()
+	}
+	return Some(p.First), Some(p.Second)
+}
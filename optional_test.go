@@ -1,6 +1,7 @@
 package optional_test
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/brnsampson/optional"
@@ -118,3 +119,69 @@ func TestTransformOr(t *testing.T) {
 	assert.Assert(t, ok)
 	assert.Equal(t, after_def, tmp)
 }
+
+func TestMap(t *testing.T) {
+	s := optional.SomeStr("8080")
+	res := optional.Map(s, func(p string) int { return len(p) })
+	assert.Assert(t, res.Match(4))
+
+	none := optional.Map(optional.NoStr(), func(p string) int { return len(p) })
+	assert.Assert(t, none.IsNone())
+
+	i := optional.Map(optional.SomeInt(42), strconv.Itoa)
+	assert.Assert(t, i.Match("42"))
+}
+
+func TestMapErr(t *testing.T) {
+	res, err := optional.MapErr(optional.SomeStr("8080"), strconv.Atoi)
+	assert.NilError(t, err)
+	assert.Assert(t, res.Match(8080))
+
+	res, err = optional.MapErr(optional.SomeStr("not a number"), strconv.Atoi)
+	assert.Assert(t, err != nil)
+	assert.Assert(t, res.IsNone())
+
+	res, err = optional.MapErr(optional.NoStr(), strconv.Atoi)
+	assert.NilError(t, err)
+	assert.Assert(t, res.IsNone())
+}
+
+func TestFlatMap(t *testing.T) {
+	parse := func(s string) optional.Option[int] {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return optional.None[int]()
+		}
+		return optional.Some(i)
+	}
+
+	assert.Assert(t, optional.FlatMap(optional.Some("12"), parse).Match(12))
+	assert.Assert(t, optional.FlatMap(optional.Some("twelve"), parse).IsNone())
+	assert.Assert(t, optional.FlatMap(optional.None[string](), parse).IsNone())
+}
+
+func TestFilter(t *testing.T) {
+	positive := func(i int) bool { return i > 0 }
+	assert.Assert(t, optional.Filter(optional.SomeInt(1), positive).Match(1))
+	assert.Assert(t, optional.Filter(optional.SomeInt(-1), positive).IsNone())
+	assert.Assert(t, optional.Filter(optional.NoInt(), positive).IsNone())
+}
+
+func TestZipUnzip(t *testing.T) {
+	host := optional.SomeStr("localhost")
+	port := optional.SomeInt(8080)
+
+	zipped := optional.Zip(host, port)
+	assert.Assert(t, zipped.Match(optional.Pair[string, int]{"localhost", 8080}))
+
+	h, p := optional.Unzip(zipped)
+	assert.Assert(t, h.Match("localhost"))
+	assert.Assert(t, p.Match(8080))
+
+	zipped = optional.Zip(host, optional.NoInt())
+	assert.Assert(t, zipped.IsNone())
+
+	h, p = optional.Unzip(zipped)
+	assert.Assert(t, h.IsNone())
+	assert.Assert(t, p.IsNone())
+}
@@ -0,0 +1,394 @@
+package optional
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const redacted = "***REDACTED***"
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	anyType           = reflect.TypeOf((*any)(nil)).Elem()
+)
+
+// redactable is implemented by every type holding a secret which must never be written by MarshalRedacted. ok is false
+// for None values, which are marshaled as normal.
+type redactable interface {
+	redactionInput() (plaintext []byte, ok bool)
+}
+
+// References are never resolved, so that redaction does no I/O. The reference is redacted in place of the secret, so a
+// fingerprint of a Secret created from a reference changes when the reference does.
+func (s Secret) redactionInput() ([]byte, bool) {
+	if ref, ok := s.Reference(); ok {
+		return []byte(ref), true
+	}
+	val, ok := s.Str.Get()
+	return []byte(val), ok
+}
+
+func (s SecretBytes) redactionInput() ([]byte, bool) {
+	var val []byte
+	err := s.Use(func(b []byte) error {
+		val = append(make([]byte, 0, len(b)), b...)
+		return nil
+	})
+	return val, err == nil
+}
+
+func (h HashedSecret) redactionInput() ([]byte, bool) {
+	val, ok := h.Get()
+	return []byte(val), ok
+}
+
+// Redactor produces what MarshalRedacted writes in place of a secret. It must not return the plaintext or anything it
+// could be recovered from.
+type Redactor func(plaintext []byte) string
+
+// HMACFingerprint returns a Redactor which writes a truncated HMAC-SHA256 of the secret, keyed with key, as
+// "hmac-sha256:<hex>". This lets operators tell whether two processes have the same secret configured, or whether it
+// changed between deploys, without revealing it. n is the number of bytes of the MAC to keep, at most 32.
+func HMACFingerprint(key []byte, n int) Redactor {
+	if n <= 0 || n > sha256.Size {
+		n = sha256.Size
+	}
+	return func(plaintext []byte) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(plaintext)
+		return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil)[:n])
+	}
+}
+
+// RedactOption configures MarshalRedacted and RedactingEncoder.
+type RedactOption func(*redactor)
+
+// WithRedactor replaces the default "***REDACTED***" placeholder with the output of f, e.g. an HMACFingerprint.
+func WithRedactor(f Redactor) RedactOption {
+	return func(r *redactor) {
+		r.redact = f
+	}
+}
+
+// MarshalRedacted marshals v to JSON the same as json.Marshal, except that every Secret, SecretBytes, and HashedSecret
+// with a value is written as "***REDACTED***", or the output of a Redactor set with WithRedactor. Secrets are found
+// anywhere in the value graph, including inside nested and embedded structs, pointers, interfaces, maps, slices, and
+// arrays, as well as inside this package's own containers such as Option, AnyOption, Nullable, and Slice. None secrets
+// are still written as null.
+//
+// Use this for debug endpoints and log lines, while normal json.Marshal keeps working for wire use.
+func MarshalRedacted(v any, opts ...RedactOption) ([]byte, error) {
+	r := newRedactor(opts)
+	val, err := r.redactRoot(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(val)
+}
+
+// RedactingEncoder is a json.Encoder which redacts secrets the same way as MarshalRedacted.
+type RedactingEncoder struct {
+	enc *json.Encoder
+	r   *redactor
+}
+
+// NewRedactingEncoder returns a RedactingEncoder that writes to w.
+func NewRedactingEncoder(w io.Writer, opts ...RedactOption) *RedactingEncoder {
+	return &RedactingEncoder{enc: json.NewEncoder(w), r: newRedactor(opts)}
+}
+
+// Encode writes the redacted JSON encoding of v to the stream, followed by a newline.
+func (e *RedactingEncoder) Encode(v any) error {
+	val, err := e.r.redactRoot(v)
+	if err != nil {
+		return err
+	}
+	return e.enc.Encode(val)
+}
+
+// SetIndent behaves the same as json.Encoder.SetIndent.
+func (e *RedactingEncoder) SetIndent(prefix, indent string) {
+	e.enc.SetIndent(prefix, indent)
+}
+
+// SetEscapeHTML behaves the same as json.Encoder.SetEscapeHTML.
+func (e *RedactingEncoder) SetEscapeHTML(on bool) {
+	e.enc.SetEscapeHTML(on)
+}
+
+type redactor struct {
+	redact Redactor
+	seen   map[uintptr]struct{}
+}
+
+func newRedactor(opts []RedactOption) *redactor {
+	r := &redactor{redact: func([]byte) string { return redacted }}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *redactor) redactRoot(v any) (any, error) {
+	r.seen = make(map[uintptr]struct{})
+	val, changed, err := r.redactValue(reflect.ValueOf(v))
+	if err != nil || !changed {
+		return v, err
+	}
+	return val.Interface(), nil
+}
+
+// redactValue returns a copy of v with every secret replaced. Subtrees without any secrets are returned as they are, so
+// changed is false if v itself can be used unmodified.
+func (r *redactor) redactValue(v reflect.Value) (reflect.Value, bool, error) {
+	if !v.IsValid() {
+		return v, false, nil
+	}
+
+	if v.CanInterface() {
+		if s, ok := v.Interface().(redactable); ok {
+			plaintext, ok := s.redactionInput()
+			if !ok {
+				return v, false, nil
+			}
+			val := r.redact(plaintext)
+			clear(plaintext)
+			return reflect.ValueOf(val), true, nil
+		}
+	}
+
+	if v.CanInterface() {
+		if sv, ok := v.Interface().(storedValuer); ok {
+			return r.redactStored(v, sv)
+		}
+	}
+
+	if isJSONLeaf(v.Type()) {
+		return v, false, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v, false, nil
+		}
+		ptr := v.Pointer()
+		if _, ok := r.seen[ptr]; ok {
+			return v, false, fmt.Errorf("cannot redact cyclic value of type %s", v.Type())
+		}
+		r.seen[ptr] = struct{}{}
+		defer delete(r.seen, ptr)
+
+		elem, changed, err := r.redactValue(v.Elem())
+		if !changed {
+			return v, false, err
+		}
+		return elem, true, err
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, false, nil
+		}
+		return r.redactValue(v.Elem())
+	case reflect.Struct:
+		return r.redactStruct(v)
+	case reflect.Map:
+		return r.redactMap(v)
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			// []byte is written as a base64 string rather than an array.
+			return v, false, nil
+		}
+		return r.redactList(v)
+	case reflect.Array:
+		return r.redactList(v)
+	default:
+		return v, false, nil
+	}
+}
+
+// redactStored looks inside the optionals of this package, which would otherwise be marshaled by their own MarshalJSON
+// methods without the redactor seeing what they hold. If nothing inside needs redacting, the optional is returned as it
+// is so that it keeps its own encoding, e.g. the format of a Time.
+func (r *redactor) redactStored(v reflect.Value, sv storedValuer) (reflect.Value, bool, error) {
+	inner, ok := sv.storedValue()
+	if !ok {
+		return v, false, nil
+	}
+
+	val, changed, err := r.redactValue(reflect.ValueOf(inner))
+	if err != nil || !changed {
+		return v, false, err
+	}
+	return val, true, nil
+}
+
+func (r *redactor) redactMap(v reflect.Value) (reflect.Value, bool, error) {
+	if v.IsNil() {
+		return v, false, nil
+	}
+
+	redactedMap := reflect.MakeMapWithSize(reflect.MapOf(v.Type().Key(), anyType), v.Len())
+	changed := false
+	iter := v.MapRange()
+	for iter.Next() {
+		val, c, err := r.redactValue(iter.Value())
+		if err != nil {
+			return v, false, err
+		}
+		changed = changed || c
+		redactedMap.SetMapIndex(iter.Key(), val)
+	}
+
+	if !changed {
+		return v, false, nil
+	}
+	return redactedMap, true, nil
+}
+
+func (r *redactor) redactList(v reflect.Value) (reflect.Value, bool, error) {
+	list := reflect.MakeSlice(reflect.SliceOf(anyType), v.Len(), v.Len())
+	changed := false
+	for i := 0; i < v.Len(); i++ {
+		val, c, err := r.redactValue(v.Index(i))
+		if err != nil {
+			return v, false, err
+		}
+		changed = changed || c
+		list.Index(i).Set(val)
+	}
+
+	if !changed {
+		return v, false, nil
+	}
+	return list, true, nil
+}
+
+// jsonField is a field which will be written by encoding/json, along with how deeply it was embedded.
+type jsonField struct {
+	key    string
+	opts   string
+	depth  int
+	tagged bool
+	value  reflect.Value
+}
+
+// redactStruct rebuilds v as a new struct type. Embedded structs are flattened the same way encoding/json does, so the
+// new struct has no embedded fields and each field is given an explicit json tag holding the original key.
+func (r *redactor) redactStruct(v reflect.Value) (reflect.Value, bool, error) {
+	var fields []jsonField
+	changed, err := r.collectFields(v, 0, &fields)
+	if err != nil || !changed {
+		return v, false, err
+	}
+
+	var structFields []reflect.StructField
+	var values []reflect.Value
+	for i, f := range dominantFields(fields) {
+		tag := f.key
+		if f.opts != "" {
+			tag += "," + f.opts
+		}
+		structFields = append(structFields, reflect.StructField{
+			Name: "F" + strconv.Itoa(i),
+			Type: f.value.Type(),
+			Tag:  reflect.StructTag(`json:` + strconv.Quote(tag)),
+		})
+		values = append(values, f.value)
+	}
+
+	rebuilt := reflect.New(reflect.StructOf(structFields)).Elem()
+	for i, val := range values {
+		rebuilt.Field(i).Set(val)
+	}
+	return rebuilt, true, nil
+}
+
+func (r *redactor) collectFields(v reflect.Value, depth int, fields *[]jsonField) (bool, error) {
+	changed := false
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fv := v.Field(i)
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !isJSONLeaf(sf.Type) {
+				// encoding/json promotes the fields of embedded structs into the parent, even unexported ones.
+				if fv.Kind() == reflect.Pointer {
+					if !sf.IsExported() || fv.IsNil() {
+						continue
+					}
+					fv = fv.Elem()
+				}
+				c, err := r.collectFields(fv, depth+1, fields)
+				if err != nil {
+					return false, err
+				}
+				changed = changed || c
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		val, c, err := r.redactValue(fv)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || c
+
+		key := name
+		if key == "" {
+			key = sf.Name
+		}
+		*fields = append(*fields, jsonField{key: key, opts: opts, depth: depth, tagged: name != "", value: val})
+	}
+	return changed, nil
+}
+
+// dominantFields applies the encoding/json rules for fields with the same key: the shallowest field wins, and if
+// there are several at that depth the single tagged one wins. Otherwise they are all dropped.
+func dominantFields(fields []jsonField) []jsonField {
+	var out []jsonField
+	for i, f := range fields {
+		dominant := true
+		for j, other := range fields {
+			if i == j || other.key != f.key {
+				continue
+			}
+			if other.depth < f.depth || (other.depth == f.depth && (other.tagged || !f.tagged)) {
+				dominant = false
+				break
+			}
+		}
+		if dominant {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// isJSONLeaf reports if encoding/json marshals values of type t with their own methods rather than by kind.
+func isJSONLeaf(t reflect.Type) bool {
+	for _, mt := range []reflect.Type{t, reflect.PointerTo(t)} {
+		if mt.Implements(jsonMarshalerType) || mt.Implements(textMarshalerType) {
+			return true
+		}
+	}
+	return false
+}
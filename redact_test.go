@@ -0,0 +1,213 @@
+package optional_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brnsampson/optional"
+	"gotest.tools/v3/assert"
+)
+
+type redactDB struct {
+	Host     optional.Str    `json:"host"`
+	Password optional.Secret `json:"password"`
+}
+
+type redactEmbedded struct {
+	APIKey optional.Secret
+}
+
+type redactConfig struct {
+	redactEmbedded
+	Name    string                     `json:"name"`
+	DB      redactDB                   `json:"db"`
+	Replica *redactDB                  `json:"replica,omitempty"`
+	Tokens  map[string]optional.Secret `json:"tokens"`
+	Keys    []optional.SecretBytes     `json:"keys"`
+	Extra   any                        `json:"extra"`
+	Hash    optional.HashedSecret      `json:"hash"`
+	Unset   optional.Secret            `json:"unset"`
+	Skipped optional.Secret            `json:"-"`
+	private optional.Secret
+}
+
+func TestMarshalRedacted(t *testing.T) {
+	hash, err := optional.HashSecretArgon2id("token", testArgon2idParams)
+	assert.NilError(t, err)
+
+	cfg := redactConfig{
+		redactEmbedded: redactEmbedded{APIKey: optional.SomeSecret("embedded-secret")},
+		Name:           "app",
+		DB:             redactDB{Host: optional.SomeStr("localhost"), Password: optional.SomeSecret("db-secret")},
+		Replica:        &redactDB{Host: optional.SomeStr("replica"), Password: optional.SomeSecret("replica-secret")},
+		Tokens:         map[string]optional.Secret{"a": optional.SomeSecret("map-secret")},
+		Keys:           []optional.SecretBytes{optional.SomeSecretBytes([]byte("bytes-secret"))},
+		Extra:          map[string]any{"nested": []any{optional.SomeSecret("any-secret")}},
+		Hash:           hash,
+		Skipped:        optional.SomeSecret("skipped"),
+		private:        optional.SomeSecret("private"),
+	}
+
+	data, err := optional.MarshalRedacted(cfg)
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(string(data), "secret"), string(data))
+	assert.Assert(t, !strings.Contains(string(data), "argon2id"), string(data))
+
+	expected := `{"APIKey":"***REDACTED***","name":"app","db":{"host":"localhost","password":"***REDACTED***"},` +
+		`"replica":{"host":"replica","password":"***REDACTED***"},"tokens":{"a":"***REDACTED***"},` +
+		`"keys":["***REDACTED***"],"extra":{"nested":["***REDACTED***"]},"hash":"***REDACTED***","unset":null}`
+	assert.Equal(t, expected, string(data))
+
+	// Normal marshaling still writes the plaintext for wire use
+	wire, err := json.Marshal(cfg.DB)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"host":"localhost","password":"db-secret"}`, string(wire))
+}
+
+func TestMarshalRedactedContainers(t *testing.T) {
+	type containers struct {
+		Keys     optional.Slice[optional.Secret]                `json:"keys"`
+		Tokens   optional.AnyOption[[]optional.Secret]          `json:"tokens"`
+		Named    optional.AnyOption[map[string]optional.Secret] `json:"named"`
+		Patch    optional.Nullable[optional.Secret]             `json:"patch"`
+		Wrapped  optional.Option[optional.Secret]               `json:"wrapped"`
+		Cleared  optional.Nullable[optional.Secret]             `json:"cleared"`
+		Plain    optional.Slice[string]                         `json:"plain"`
+		Deadline optional.Time                                  `json:"deadline"`
+	}
+
+	c := containers{
+		Keys:     optional.SomeSlice(optional.SomeSecret("slice-secret"), optional.NoSecret()),
+		Tokens:   optional.SomeAny([]optional.Secret{optional.SomeSecret("any-secret")}),
+		Named:    optional.SomeAny(map[string]optional.Secret{"a": optional.SomeSecret("map-secret")}),
+		Patch:    optional.Present(optional.SomeSecret("nullable-secret")),
+		Wrapped:  optional.Some(optional.SomeSecret("option-secret")),
+		Cleared:  optional.Null[optional.Secret](),
+		Plain:    optional.SomeStrs("a"),
+		Deadline: optional.SomeTime(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+	}
+
+	data, err := optional.MarshalRedacted(c)
+	assert.NilError(t, err)
+	expected := `{"keys":["***REDACTED***",null],"tokens":["***REDACTED***"],"named":{"a":"***REDACTED***"},` +
+		`"patch":"***REDACTED***","wrapped":"***REDACTED***","cleared":null,"plain":["a"],"deadline":"2024-01-02T03:04:05Z"}`
+	assert.Equal(t, expected, string(data))
+}
+
+func TestMarshalRedactedUnchanged(t *testing.T) {
+	type plain struct {
+		Host  optional.Str `json:"host"`
+		Ports []int        `json:"ports,omitempty"`
+		Raw   []byte       `json:"raw"`
+	}
+	v := &plain{Host: optional.SomeStr("localhost"), Raw: []byte("hi")}
+
+	redacted, err := optional.MarshalRedacted(v)
+	assert.NilError(t, err)
+	wire, err := json.Marshal(v)
+	assert.NilError(t, err)
+	assert.Equal(t, string(wire), string(redacted))
+
+	top, err := optional.MarshalRedacted(optional.SomeSecret("top"))
+	assert.NilError(t, err)
+	assert.Equal(t, `"***REDACTED***"`, string(top))
+
+	null, err := optional.MarshalRedacted(nil)
+	assert.NilError(t, err)
+	assert.Equal(t, "null", string(null))
+}
+
+func TestMarshalRedactedFingerprint(t *testing.T) {
+	fingerprint := optional.HMACFingerprint([]byte("key"), 8)
+	a, err := optional.MarshalRedacted(redactDB{Password: optional.SomeSecret("hunter2")}, optional.WithRedactor(fingerprint))
+	assert.NilError(t, err)
+	b, err := optional.MarshalRedacted(redactDB{Password: optional.SomeSecret("hunter2")}, optional.WithRedactor(fingerprint))
+	assert.NilError(t, err)
+	c, err := optional.MarshalRedacted(redactDB{Password: optional.SomeSecret("hunter3")}, optional.WithRedactor(fingerprint))
+	assert.NilError(t, err)
+
+	assert.Equal(t, string(a), string(b))
+	assert.Assert(t, string(a) != string(c))
+	assert.Assert(t, strings.Contains(string(a), `"password":"hmac-sha256:`))
+	assert.Assert(t, !strings.Contains(string(a), "hunter2"))
+
+	var out struct{ Password string }
+	assert.NilError(t, json.Unmarshal(a, &out))
+	assert.Equal(t, len("hmac-sha256:")+16, len(out.Password))
+}
+
+func TestMarshalRedactedCycle(t *testing.T) {
+	type node struct {
+		Secret optional.Secret
+		Next   *node
+	}
+	n := &node{Secret: optional.SomeSecret("hunter2")}
+	n.Next = n
+
+	_, err := optional.MarshalRedacted(n)
+	assert.ErrorContains(t, err, "cyclic")
+}
+
+func TestMarshalRedactedConflictingFields(t *testing.T) {
+	type inner struct {
+		Name     string
+		Password optional.Secret
+	}
+	type other struct {
+		Name string
+	}
+	type outer struct {
+		inner
+		other
+		Password optional.Secret
+	}
+
+	v := outer{inner: inner{Name: "a", Password: optional.SomeSecret("inner")}, other: other{Name: "b"},
+		Password: optional.SomeSecret("outer")}
+
+	redacted, err := optional.MarshalRedacted(v)
+	assert.NilError(t, err)
+	// Name is ambiguous so encoding/json drops it, and the shallower Password wins
+	assert.Equal(t, `{"Password":"***REDACTED***"}`, string(redacted))
+
+	wire, err := json.Marshal(v)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"Password":"outer"}`, string(wire))
+}
+
+func TestRedactingEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := optional.NewRedactingEncoder(&buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(true)
+
+	assert.NilError(t, enc.Encode(redactDB{Host: optional.SomeStr("db"), Password: optional.SomeSecret("hunter2")}))
+	assert.Equal(t, "{\n  \"host\": \"db\",\n  \"password\": \"***REDACTED***\"\n}\n", buf.String())
+}
+
+func TestMarshalRedactedDoesNotResolveReferences(t *testing.T) {
+	var resolved int
+	optional.RegisterSecretProvider("counted", optional.SecretProviderFunc(func(ref *url.URL) (string, error) {
+		resolved++
+		return "hunter2", nil
+	}))
+	defer optional.RegisterSecretProvider("counted", nil)
+
+	ref, err := optional.SecretRef("counted:db")
+	assert.NilError(t, err)
+	cfg := redactDB{Password: ref}
+
+	data, err := optional.MarshalRedacted(cfg)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"host":null,"password":"***REDACTED***"}`, string(data))
+
+	fingerprint := optional.HMACFingerprint([]byte("key"), 8)
+	data, err = optional.MarshalRedacted(cfg, optional.WithRedactor(fingerprint))
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(data), fingerprint([]byte("counted:db"))), string(data))
+	assert.Equal(t, 0, resolved)
+}
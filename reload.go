@@ -0,0 +1,235 @@
+package optional
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Loader produces a config from a single source, such as flags, a config file, or env vars. Any fields which the
+// source does not set should be left as None.
+type Loader[C any] func() (C, error)
+
+// ChangeKind describes how an optional field changed between two configs.
+type ChangeKind int
+
+const (
+	// Added means the field went from None to Some.
+	Added ChangeKind = iota
+	// Removed means the field went from Some to None.
+	Removed
+	// Modified means the field was Some in both configs, but the value changed.
+	Modified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "Added"
+	case Removed:
+		return "Removed"
+	case Modified:
+		return "Modified"
+	default:
+		return fmt.Sprintf("ChangeKind(%d)", int(k))
+	}
+}
+
+// FieldChange describes a single optional field which differs between two configs. Path is the dotted path of field
+// names from the root of the config, e.g. "TLS.Port". Old and New hold the optional fields themselves rather than the
+// unwrapped values so that types like Secret stay redacted if a change is logged.
+type FieldChange struct {
+	Path string
+	Kind ChangeKind
+	Old  any
+	New  any
+}
+
+// Changes is the set of optional fields which differ between two configs.
+type Changes []FieldChange
+
+// Changed returns true if the field at path was added, removed, or modified.
+func (c Changes) Changed(path string) bool {
+	_, ok := c.Get(path)
+	return ok
+}
+
+// Get returns the change to the field at path, if there was one.
+func (c Changes) Get(path string) (FieldChange, bool) {
+	for _, change := range c {
+		if change.Path == path {
+			return change, true
+		}
+	}
+	return FieldChange{}, false
+}
+
+// Reloadable holds a config struct made of optionals which can be safely read while being reloaded from its sources,
+// for example in response to a SIGHUP. The config is rebuilt by calling each Loader and merging the results in order
+// with Merge, so the first Loader given has the highest precedence.
+type Reloadable[C any] struct {
+	current atomic.Pointer[C]
+
+	mu          sync.Mutex
+	loaders     []Loader[C]
+	subscribers []func(C, Changes)
+}
+
+// NewReloadable creates a Reloadable and performs the initial load from loaders. C must be a struct type.
+func NewReloadable[C any](loaders ...Loader[C]) (*Reloadable[C], error) {
+	var zero C
+	if reflect.TypeOf(zero) == nil || reflect.TypeOf(zero).Kind() != reflect.Struct {
+		return nil, fmt.Errorf("reloadable config must be a struct, got %T", zero)
+	}
+
+	r := &Reloadable[C]{loaders: loaders}
+	r.current.Store(&zero)
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Load returns a copy of the current config. It is safe to call concurrently with Reload.
+func (r *Reloadable[C]) Load() C {
+	return *r.current.Load()
+}
+
+// Subscribe registers f to be called after every Reload which changes at least one optional field. f is passed the new
+// config along with the changes from the previous one. Subscribers are called synchronously from Reload in the order
+// they were registered, after the new config has been swapped in and without any lock held, so they are free to call
+// Load, Subscribe, or even Reload.
+func (r *Reloadable[C]) Subscribe(f func(C, Changes)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, f)
+}
+
+// Reload re-runs the source chain and atomically swaps in the resulting config. If any loaders are passed, they are run
+// instead of the current chain, and replace it for all future reloads once they succeed. If any loader returns an
+// error, the current config and chain are kept and the error is returned.
+func (r *Reloadable[C]) Reload(loaders ...Loader[C]) error {
+	next, changes, subscribers, err := r.swap(loaders)
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+
+	for _, f := range subscribers {
+		f(next, changes)
+	}
+	return nil
+}
+
+// swap does the locked part of Reload. It returns a copy of the subscribers so that they can be notified after the
+// lock is released.
+func (r *Reloadable[C]) swap(loaders []Loader[C]) (C, Changes, []func(C, Changes), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	chain := r.loaders
+	if len(loaders) > 0 {
+		chain = loaders
+	}
+
+	var next C
+	for i, load := range chain {
+		src, err := load()
+		if err != nil {
+			return next, nil, nil, fmt.Errorf("config loader %d failed: %w", i, err)
+		}
+		if err := Merge(&next, src); err != nil {
+			return next, nil, nil, err
+		}
+	}
+
+	r.loaders = chain
+	prev := r.current.Swap(&next)
+	return next, Diff(*prev, next), slices.Clone(r.subscribers), nil
+}
+
+// Diff compares every optional field of two structs of the same type, recursing into nested structs the same way as
+// Merge, and returns the fields which were added, removed, or modified. time.Time values are compared with
+// time.Time.Equal so that the same instant in different locations is not treated as a change. Values are compared as
+// they are stored, so Diff never resolves a Secret's reference; a Secret is only modified if its reference changed.
+func Diff[C any](prev, next C) Changes {
+	var changes Changes
+	diffStruct(reflect.ValueOf(prev), reflect.ValueOf(next), "", &changes)
+	return changes
+}
+
+func diffStruct(prev, next reflect.Value, prefix string, changes *Changes) {
+	if prev.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < prev.NumField(); i++ {
+		sf := prev.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		path := sf.Name
+		if prefix != "" {
+			path = prefix + "." + sf.Name
+		}
+
+		of := prev.Field(i)
+		nf := next.Field(i)
+		if _, ok := of.Interface().(someable); ok {
+			oSome, nSome := isStored(of), isStored(nf)
+			change := FieldChange{Path: path, Old: of.Interface(), New: nf.Interface()}
+			switch {
+			case !oSome && nSome:
+				change.Kind = Added
+			case oSome && !nSome:
+				change.Kind = Removed
+			case oSome && nSome && !sameInner(of, nf):
+				change.Kind = Modified
+			default:
+				continue
+			}
+			*changes = append(*changes, change)
+		} else if of.Kind() == reflect.Struct {
+			diffStruct(of, nf, path, changes)
+		}
+	}
+}
+
+// storedValuer is implemented by Option, AnyOption, and Nullable, and so by every wrapper which embeds them. storedValue
+// returns the wrapped value exactly as it is stored, without any of the side effects Get and IsSome may have on some
+// wrappers.
+type storedValuer interface {
+	storedValue() (any, bool)
+}
+
+// isStored reports if an optional holds a value without resolving anything, e.g. a Secret holding a reference is Some.
+func isStored(v reflect.Value) bool {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return false
+	}
+	if sv, ok := v.Interface().(storedValuer); ok {
+		_, some := sv.storedValue()
+		return some
+	}
+	return v.Interface().(someable).IsSome()
+}
+
+// sameInner compares the values stored in two optionals of the same type.
+func sameInner(a, b reflect.Value) bool {
+	switch av := a.Interface().(type) {
+	case SecretBytes:
+		return av.Equal(b.Interface().(SecretBytes))
+	case storedValuer:
+		at, _ := av.storedValue()
+		bt, _ := b.Interface().(storedValuer).storedValue()
+		if t, ok := at.(time.Time); ok {
+			return t.Equal(bt.(time.Time))
+		}
+		return reflect.DeepEqual(at, bt)
+	default:
+		return reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+}
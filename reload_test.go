@@ -0,0 +1,220 @@
+package optional_test
+
+import (
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brnsampson/optional"
+	"gotest.tools/v3/assert"
+)
+
+type reloadTestTLS struct {
+	Cert optional.Str
+}
+
+type reloadTestConfig struct {
+	Host     optional.Str
+	Port     optional.Int
+	Started  optional.Time
+	Password optional.Secret
+	TLS      reloadTestTLS
+}
+
+func TestReloadable(t *testing.T) {
+	flags := reloadTestConfig{Port: optional.SomeInt(8080)}
+	file := reloadTestConfig{Host: optional.SomeStr("localhost"), Port: optional.SomeInt(1443)}
+
+	r, err := optional.NewReloadable(
+		func() (reloadTestConfig, error) { return flags, nil },
+		func() (reloadTestConfig, error) { return file, nil },
+	)
+	assert.NilError(t, err)
+
+	conf := r.Load()
+	assert.Assert(t, conf.Port.Match(8080))
+	assert.Assert(t, conf.Host.Match("localhost"))
+
+	var calls int
+	var lastChanges optional.Changes
+	r.Subscribe(func(c reloadTestConfig, changes optional.Changes) {
+		calls++
+		lastChanges = changes
+		assert.Assert(t, c.Host.Match("example.com"))
+	})
+
+	// Reloading without any changes doesn't notify
+	err = r.Reload()
+	assert.NilError(t, err)
+	assert.Equal(t, 0, calls)
+
+	file.Host = optional.SomeStr("example.com")
+	file.Password = optional.SomeSecret("hunter2")
+	file.TLS.Cert = optional.SomeStr("/etc/cert.pem")
+	err = r.Reload()
+	assert.NilError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 3, len(lastChanges))
+
+	change, ok := lastChanges.Get("Host")
+	assert.Assert(t, ok)
+	assert.Equal(t, optional.Modified, change.Kind)
+	change, ok = lastChanges.Get("Password")
+	assert.Assert(t, ok)
+	assert.Equal(t, optional.Added, change.Kind)
+	assert.Assert(t, lastChanges.Changed("TLS.Cert"))
+	assert.Assert(t, !lastChanges.Changed("Port"))
+
+	// The file value for Port is hidden by the flag, so changing it is not a change to the config
+	file.Port = optional.SomeInt(22)
+	err = r.Reload()
+	assert.NilError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestReloadableError(t *testing.T) {
+	fail := false
+	load := func() (reloadTestConfig, error) {
+		if fail {
+			return reloadTestConfig{}, errors.New("file went missing")
+		}
+		return reloadTestConfig{Port: optional.SomeInt(8080)}, nil
+	}
+
+	r, err := optional.NewReloadable(load)
+	assert.NilError(t, err)
+
+	fail = true
+	err = r.Reload()
+	assert.ErrorContains(t, err, "file went missing")
+	assert.Assert(t, r.Load().Port.Match(8080))
+
+	// Replacing the loaders
+	err = r.Reload(func() (reloadTestConfig, error) { return reloadTestConfig{}, nil })
+	assert.NilError(t, err)
+	assert.Assert(t, r.Load().Port.IsNone())
+}
+
+func TestReloadableFailedReplacementKeepsChain(t *testing.T) {
+	port := 8080
+	r, err := optional.NewReloadable(func() (reloadTestConfig, error) {
+		return reloadTestConfig{Port: optional.SomeInt(port)}, nil
+	})
+	assert.NilError(t, err)
+
+	err = r.Reload(func() (reloadTestConfig, error) { return reloadTestConfig{}, errors.New("bad loader") })
+	assert.ErrorContains(t, err, "bad loader")
+	assert.Assert(t, r.Load().Port.Match(8080))
+
+	// The original chain is still used by later reloads
+	port = 9090
+	assert.NilError(t, r.Reload())
+	assert.Assert(t, r.Load().Port.Match(9090))
+}
+
+func TestReloadableConcurrentLoad(t *testing.T) {
+	port := 0
+	r, err := optional.NewReloadable(func() (reloadTestConfig, error) {
+		port++
+		return reloadTestConfig{Port: optional.SomeInt(port)}, nil
+	})
+	assert.NilError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.Assert(t, r.Load().Port.IsSome())
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		assert.NilError(t, r.Reload())
+	}
+	wg.Wait()
+	assert.Assert(t, r.Load().Port.Match(11))
+}
+
+func TestDiff(t *testing.T) {
+	now := time.Now()
+	prev := reloadTestConfig{Port: optional.SomeInt(1), Started: optional.SomeTime(now)}
+	next := reloadTestConfig{Host: optional.SomeStr("localhost"), Started: optional.SomeTime(now.UTC())}
+
+	changes := optional.Diff(prev, next)
+	assert.Equal(t, 2, len(changes))
+	change, ok := changes.Get("Port")
+	assert.Assert(t, ok)
+	assert.Equal(t, optional.Removed, change.Kind)
+	assert.Assert(t, change.Old.(optional.Int).Match(1))
+	assert.Assert(t, changes.Changed("Host"))
+	assert.Assert(t, !changes.Changed("Started"))
+}
+
+func TestReloadableReentrantSubscriber(t *testing.T) {
+	port := 0
+	r, err := optional.NewReloadable(func() (reloadTestConfig, error) {
+		port++
+		return reloadTestConfig{Port: optional.SomeInt(port)}, nil
+	})
+	assert.NilError(t, err)
+
+	// Subscribers may subscribe and reload from inside a notification without deadlocking
+	var later int
+	r.Subscribe(func(c reloadTestConfig, _ optional.Changes) {
+		if c.Port.Match(2) {
+			r.Subscribe(func(reloadTestConfig, optional.Changes) { later++ })
+			assert.NilError(t, r.Reload())
+		}
+	})
+	assert.NilError(t, r.Reload())
+	assert.Assert(t, r.Load().Port.Match(3))
+	assert.Equal(t, 1, later)
+}
+
+func TestDiffDoesNotResolveSecrets(t *testing.T) {
+	var resolved int
+	optional.RegisterSecretProvider("counted", optional.SecretProviderFunc(func(ref *url.URL) (string, error) {
+		resolved++
+		return "hunter2", nil
+	}))
+	defer optional.RegisterSecretProvider("counted", nil)
+
+	a, err := optional.SecretRef("counted:a")
+	assert.NilError(t, err)
+	b, err := optional.SecretRef("counted:b")
+	assert.NilError(t, err)
+
+	type config struct {
+		Password optional.Secret
+		Key      optional.SecretBytes
+	}
+	prev := config{Password: a, Key: optional.SomeSecretBytes([]byte("k1"))}
+	same := config{Password: a, Key: optional.SomeSecretBytes([]byte("k1"))}
+	next := config{Password: b, Key: optional.SomeSecretBytes([]byte("k2"))}
+
+	assert.Equal(t, 0, len(optional.Diff(prev, same)))
+	changes := optional.Diff(prev, next)
+	assert.Assert(t, changes.Changed("Password"))
+	assert.Assert(t, changes.Changed("Key"))
+	assert.Equal(t, 0, resolved)
+
+	// A reference is never the same as a stored secret with the same text
+	literal := config{Password: optional.SomeSecret("counted:a"), Key: prev.Key}
+	assert.Assert(t, optional.Diff(prev, literal).Changed("Password"))
+}
+
+func TestDiffPointerFields(t *testing.T) {
+	type config struct {
+		Port *optional.Int
+	}
+
+	port := optional.SomeInt(80)
+	changes := optional.Diff(config{}, config{Port: &port})
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, optional.Added, changes[0].Kind)
+	assert.Equal(t, 0, len(optional.Diff(config{}, config{})))
+}
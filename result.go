@@ -0,0 +1,168 @@
+package optional
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Result holds either a value or the error which prevented getting one. It pairs naturally with the Optional types:
+// a loader can return a Result[Int] for each field, where an Ok result may still hold None if the field was not
+// configured, and the errors of every field can be reported at once with JoinResults.
+//
+// Unlike Option, T may be any type, so a Result can hold a Slice, a StrMap, or a plain struct. Methods can not introduce
+// new type parameters in go, so Map and AndThen keep the type of the Result. Use MapResult and AndThenResult to change
+// it.
+type Result[T any] struct {
+	val T
+	err error
+}
+
+// Ok returns a successful Result holding val.
+func Ok[T any](val T) Result[T] {
+	return Result[T]{val: val}
+}
+
+// Err returns a failed Result holding err. Passing a nil error still gives a failed Result, since that is almost
+// certainly a bug in the caller.
+func Err[T any](err error) Result[T] {
+	if err == nil {
+		err = optionalError("Err called with a nil error")
+	}
+	return Result[T]{err: err}
+}
+
+// ResultOf converts the usual go (value, error) return pair into a Result, e.g. optional.ResultOf(strconv.Atoi(s)).
+func ResultOf[T any](val T, err error) Result[T] {
+	if err != nil {
+		return Err[T](err)
+	}
+	return Ok(val)
+}
+
+// OkOr returns Ok holding the value of a Some Optional or AnyOption, or err if it is None.
+func OkOr[T any](opt interface{ Get() (T, bool) }, err error) Result[T] {
+	val, ok := opt.Get()
+	if !ok {
+		return Err[T](err)
+	}
+	return Ok(val)
+}
+
+// OkOrElse is the same as OkOr, except f is only called to build the error when opt is None.
+func OkOrElse[T any](opt interface{ Get() (T, bool) }, f func() error) Result[T] {
+	val, ok := opt.Get()
+	if !ok {
+		return Err[T](f())
+	}
+	return Ok(val)
+}
+
+// ParseResult sets a copy of opt from text with its Set method, so that a loader can build a Result for a field in one
+// line. opt is usually a None value, and any settings it carries, such as the formats of a Duration, are kept.
+//
+//	port := optional.ParseResult(optional.NoInt(), os.Getenv("PORT")).WithContext("PORT")
+func ParseResult[O any, P interface {
+	*O
+	Set(string) error
+}](opt O, text string) Result[O] {
+	if err := P(&opt).Set(text); err != nil {
+		return Err[O](err)
+	}
+	return Ok(opt)
+}
+
+func (r Result[T]) IsOk() bool {
+	return r.err == nil
+}
+
+func (r Result[T]) IsErr() bool {
+	return r.err != nil
+}
+
+// Get returns the value and error as the usual go pair. The value is the zero value of T if the Result failed.
+func (r Result[T]) Get() (T, error) {
+	return r.val, r.err
+}
+
+// MustGet returns the value of an Ok Result and panics with the error otherwise.
+func (r Result[T]) MustGet() T {
+	if r.err != nil {
+		panic(fmt.Sprintf("Attempted to call MustGet on a failed Result: %v", r.err))
+	}
+	return r.val
+}
+
+// Err returns the error of a failed Result, or nil if it is Ok.
+func (r Result[T]) Err() error {
+	return r.err
+}
+
+// Option converts the Result into an AnyOption, since T need not be comparable. Ok values become Some and the error is
+// discarded.
+func (r Result[T]) Option() AnyOption[T] {
+	if r.err != nil {
+		return NoneAny[T]()
+	}
+	return SomeAny(r.val)
+}
+
+// WithContext returns a copy of the Result with its error prefixed by msg, e.g. the name of the field being loaded. The
+// original error can still be found with errors.Is and errors.As. Ok Results are returned unchanged.
+func (r Result[T]) WithContext(msg string) Result[T] {
+	if r.err == nil {
+		return r
+	}
+	return Result[T]{err: fmt.Errorf("%s: %w", msg, r.err)}
+}
+
+// Map applies f to the value of an Ok Result. Failed Results are returned unchanged.
+func (r Result[T]) Map(f func(T) T) Result[T] {
+	return MapResult(r, f)
+}
+
+// AndThen calls f with the value of an Ok Result and returns what it returns, which allows chaining steps which may
+// fail. Failed Results are returned unchanged without calling f.
+func (r Result[T]) AndThen(f func(T) Result[T]) Result[T] {
+	return AndThenResult(r, f)
+}
+
+// OrElse calls f with the error of a failed Result and returns what it returns, which allows recovering from an error
+// or replacing it. Ok Results are returned unchanged without calling f.
+func (r Result[T]) OrElse(f func(error) Result[T]) Result[T] {
+	if r.err == nil {
+		return r
+	}
+	return f(r.err)
+}
+
+// MapResult is the same as Result.Map, except f may change the type of the value.
+func MapResult[T, U any](r Result[T], f func(T) U) Result[U] {
+	if r.err != nil {
+		return Result[U]{err: r.err}
+	}
+	return Ok(f(r.val))
+}
+
+// AndThenResult is the same as Result.AndThen, except f may change the type of the value.
+func AndThenResult[T, U any](r Result[T], f func(T) Result[U]) Result[U] {
+	if r.err != nil {
+		return Result[U]{err: r.err}
+	}
+	return f(r.val)
+}
+
+// JoinResults returns the errors of every failed Result joined with errors.Join, or nil if they are all Ok. The Results
+// may be of different types, so a loader can check every field at once:
+//
+//	if err := optional.JoinResults(port, host, timeout); err != nil {
+//		return err
+//	}
+func JoinResults(results ...interface{ Err() error }) error {
+	var errs []error
+	for _, r := range results {
+		if err := r.Err(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
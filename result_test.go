@@ -0,0 +1,132 @@
+package optional_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/brnsampson/optional"
+	"gotest.tools/v3/assert"
+)
+
+var errMissing = errors.New("missing")
+
+func TestResultOkErr(t *testing.T) {
+	ok := optional.Ok(42)
+	assert.Assert(t, ok.IsOk())
+	assert.Assert(t, !ok.IsErr())
+	assert.NilError(t, ok.Err())
+	assert.Equal(t, 42, ok.MustGet())
+
+	failed := optional.Err[int](errMissing)
+	assert.Assert(t, failed.IsErr())
+	assert.ErrorIs(t, failed.Err(), errMissing)
+	val, err := failed.Get()
+	assert.Equal(t, 0, val)
+	assert.ErrorIs(t, err, errMissing)
+	assert.Assert(t, func() (panicked bool) {
+		defer func() { panicked = recover() != nil }()
+		failed.MustGet()
+		return false
+	}())
+
+	// A nil error is still a failure
+	assert.Assert(t, optional.Err[int](nil).IsErr())
+
+	assert.Equal(t, 12, optional.ResultOf(strconv.Atoi("12")).MustGet())
+	assert.Assert(t, optional.ResultOf(strconv.Atoi("twelve")).IsErr())
+}
+
+func TestResultCombinators(t *testing.T) {
+	double := func(i int) int { return i * 2 }
+	positive := func(i int) optional.Result[int] {
+		if i <= 0 {
+			return optional.Err[int](errors.New("not positive"))
+		}
+		return optional.Ok(i)
+	}
+
+	assert.Equal(t, 4, optional.Ok(2).Map(double).MustGet())
+	assert.ErrorIs(t, optional.Err[int](errMissing).Map(double).Err(), errMissing)
+
+	assert.Equal(t, 2, optional.Ok(2).AndThen(positive).MustGet())
+	assert.ErrorContains(t, optional.Ok(-2).AndThen(positive).Err(), "not positive")
+
+	called := false
+	positive2 := func(i int) optional.Result[int] { called = true; return positive(i) }
+	assert.ErrorIs(t, optional.Err[int](errMissing).AndThen(positive2).Err(), errMissing)
+	assert.Assert(t, !called, "AndThen called f for a failed Result")
+
+	recovered := optional.Err[int](errMissing).OrElse(func(err error) optional.Result[int] {
+		if errors.Is(err, errMissing) {
+			return optional.Ok(80)
+		}
+		return optional.Err[int](err)
+	})
+	assert.Equal(t, 80, recovered.MustGet())
+	assert.Equal(t, 1, optional.Ok(1).OrElse(func(error) optional.Result[int] { return optional.Ok(2) }).MustGet())
+
+	str := optional.MapResult(optional.Ok(7), strconv.Itoa)
+	assert.Equal(t, "7", str.MustGet())
+
+	parsed := optional.AndThenResult(optional.Ok("9"), func(s string) optional.Result[int] {
+		return optional.ResultOf(strconv.Atoi(s))
+	})
+	assert.Equal(t, 9, parsed.MustGet())
+}
+
+func TestResultOption(t *testing.T) {
+	assert.Equal(t, "a", optional.Ok("a").Option().MustGet())
+	assert.Assert(t, optional.Err[string](errMissing).Option().IsNone())
+
+	res := optional.OkOr(optional.SomeInt(3), errMissing)
+	assert.Equal(t, 3, res.MustGet())
+	res = optional.OkOr(optional.NoInt(), errMissing)
+	assert.ErrorIs(t, res.Err(), errMissing)
+
+	called := false
+	res = optional.OkOrElse(optional.SomeInt(3), func() error { called = true; return errMissing })
+	assert.Assert(t, res.IsOk())
+	assert.Assert(t, !called, "OkOrElse built an error for a Some value")
+	res = optional.OkOrElse(optional.NoInt(), func() error { return errMissing })
+	assert.ErrorIs(t, res.Err(), errMissing)
+
+	// AnyOption values work as well
+	strs := optional.OkOr(optional.SomeAny([]string{"a"}), errMissing)
+	assert.DeepEqual(t, []string{"a"}, strs.MustGet())
+}
+
+func TestResultLoader(t *testing.T) {
+	port := optional.ParseResult(optional.NoInt(), "8080").WithContext("PORT")
+	assert.Equal(t, 8080, port.MustGet().MustGet())
+
+	// An Ok Result may still hold None when the field was not configured
+	unset := optional.ParseResult(optional.NoInt(), "none")
+	assert.Assert(t, unset.IsOk())
+	assert.Assert(t, unset.MustGet().IsNone())
+
+	// The settings of the given value are kept
+	timeout := optional.ParseResult(optional.NoDuration().WithFormat(optional.DURATION_ISO8601_FORMAT), "90s")
+	assert.Equal(t, "PT1M30S", timeout.MustGet().String())
+
+	start := optional.ParseResult(optional.NoTime(), "2024-01-02T03:04:05Z")
+	assert.Assert(t, start.MustGet().MustGet().Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	// Types which are not comparable can be held as well
+	hosts := optional.ParseResult(optional.NoSlice[string](), "a,b")
+	assert.DeepEqual(t, []string{"a", "b"}, hosts.MustGet().MustGet())
+	assert.DeepEqual(t, []string{"a", "b"}, hosts.Option().MustGet().MustGet())
+
+	badPort := optional.ParseResult(optional.NoInt(), "http").WithContext("PORT")
+	badTimeout := optional.ParseResult(optional.NoDuration(), "soon").WithContext("TIMEOUT")
+	host := optional.ParseResult(optional.NoStr(), "localhost")
+
+	err := optional.JoinResults(badPort, host, badTimeout)
+	assert.ErrorContains(t, err, "PORT: ")
+	assert.ErrorContains(t, err, "TIMEOUT: ")
+	var numErr *strconv.NumError
+	assert.Assert(t, errors.As(err, &numErr))
+
+	assert.NilError(t, optional.JoinResults(port, host))
+}
@@ -2,7 +2,9 @@ package optional
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
+	"iter"
 	"log/slog"
 )
 
@@ -10,9 +12,19 @@ import (
 // information. The value may still be marshaled into messages,
 // but when printing to the console or logs it should be redacted.
 //
-// TODO: We should make this automatically encrypt secrets at rest.
+// Secrets are encrypted at rest when a SecretCipher is available, either
+// one given to the Secret with WithCipher or the process wide default set
+// with SetDefaultSecretCipher. Value then writes an envelope holding the
+// ciphertext and key ID instead of the plaintext, and Scan decrypts it.
+//
+// A Secret may also hold a reference to a secret stored elsewhere, such as
+// file:///run/secrets/db_password or env:DB_PASSWORD, instead of the secret
+// itself. See SecretRef for details.
 type Secret struct {
 	Str
+	cipher SecretCipher
+	ref    *secretRef
+	refs   bool
 }
 
 // MakeSecret creates a Secret from a pointer to Str optional then clears
@@ -26,15 +38,204 @@ func MakeSecret(str *Str) Secret {
 		str.Clear()
 	}
 
-	return Secret{tmp}
+	return Secret{Str: tmp}
 }
 
 func SomeSecret(value string) Secret {
-	return Secret{SomeStr(value)}
+	return Secret{Str: SomeStr(value)}
 }
 
 func NoSecret() Secret {
-	return Secret{NoStr()}
+	return Secret{Str: NoStr()}
+}
+
+// WithCipher returns a copy of the Secret which uses c instead of the default SecretCipher.
+func (s Secret) WithCipher(c SecretCipher) Secret {
+	s.cipher = c
+	return s
+}
+
+// SecretRef creates a Secret from a reference to a secret stored elsewhere. The reference is a URI whose scheme has a
+// registered SecretProvider; file:///path and env:VAR are supported out of the box. The reference is resolved lazily
+// the first time the value is needed, and the result is cached and shared by every copy of the Secret. If resolution
+// fails, the Secret is None and Resolve returns the error. Failures are not cached, so the reference is tried again the
+// next time the value is needed.
+//
+// MarshalText writes the reference rather than the resolved secret so that it round trips through config files, but
+// only Secrets returned by WithReferences read it back as a reference. See WithReferences for why.
+func SecretRef(ref string) (Secret, error) {
+	if _, ok := parseSecretRef(ref); !ok {
+		return NoSecret(), fmt.Errorf("%q is not a secret reference with a registered provider", ref)
+	}
+	var s Secret
+	s.setRef(ref)
+	return s, nil
+}
+
+// WithReferences returns a copy of the Secret which accepts references in Set, UnmarshalText, UnmarshalJSON, and
+// UnmarshalTOML, the same as SecretRef. Otherwise text such as env:HOME is always a literal secret, since anyone who
+// could write a flag, config file, or request body would be able to have the process read any file or environment
+// variable it can access into the Secret.
+func (s Secret) WithReferences() Secret {
+	s.refs = true
+	return s
+}
+
+func (s *Secret) setRef(ref string) {
+	s.Str.Clear()
+	s.ref = &secretRef{uri: ref}
+}
+
+// Reference returns the reference the Secret was created from, if any.
+func (s Secret) Reference() (ref string, ok bool) {
+	if s.ref == nil {
+		return "", false
+	}
+	return s.ref.uri, true
+}
+
+// Resolve returns the secret, resolving its reference if it has one. Unlike Get, it reports why a reference could not
+// be resolved. None Secrets return an error as well.
+func (s Secret) Resolve() (string, error) {
+	if s.ref != nil {
+		return s.ref.resolve()
+	}
+	val, ok := s.Str.Get()
+	if !ok {
+		return "", optionalError("Attempted to Resolve Secret with None value")
+	}
+	return val, nil
+}
+
+// IsSome is true for Secrets holding a value, or a reference which resolves. The reference is resolved when IsSome is
+// called, the same as Get, so the two always agree.
+func (s Secret) IsSome() bool {
+	_, ok := s.Get()
+	return ok
+}
+
+func (s Secret) IsNone() bool {
+	return !s.IsSome()
+}
+
+// IsZero never resolves references. A Secret holding a reference is not zero, even if the reference does not resolve.
+func (s Secret) IsZero() bool {
+	return s.ref == nil && s.Str.IsZero()
+}
+
+// Clone resolves any reference so that the copy holds the secret itself.
+func (s Secret) Clone() Optional[string] {
+	if val, ok := s.Get(); ok {
+		return Some(val)
+	}
+	return None[string]()
+}
+
+func (s Secret) MutableClone() MutableOptional[string] {
+	tmp := s
+	return &tmp
+}
+
+// Get resolves the Secret's reference if it has one. A reference which fails to resolve is reported as None.
+func (s Secret) Get() (val string, ok bool) {
+	if s.ref != nil {
+		val, err := s.ref.resolve()
+		return val, err == nil
+	}
+	return s.Str.Get()
+}
+
+// secretRefKey is what storedValue returns for references, so that a reference is never equal to a stored secret which
+// happens to have the same text.
+type secretRefKey string
+
+// storedValue returns the reference itself for Secrets created from one, so that comparing Secrets never resolves it.
+func (s Secret) storedValue() (any, bool) {
+	if s.ref != nil {
+		return secretRefKey(s.ref.uri), true
+	}
+	return s.Str.storedValue()
+}
+
+// All yields the secret once if it has a value, resolving references the same as Get.
+func (s Secret) All() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		if val, ok := s.Get(); ok {
+			yield(val)
+		}
+	}
+}
+
+func (s Secret) MustGet() string {
+	val, err := s.Resolve()
+	if err != nil {
+		panic(fmt.Sprintf("Attempted to call MustGet on a Secret without a value: %v", err))
+	}
+	return val
+}
+
+// Match compares the secret to probe in constant time, so that checking a token against a Secret does not reveal how
+// much of it was correct. The comparison does not leak the length of the secret either.
+func (s Secret) Match(probe string) bool {
+	val, ok := s.Get()
+	return ok && secretsEqual([]byte(val), []byte(probe))
+}
+
+// Equal compares two Secrets in constant time. Two None Secrets are equal, while None is never equal to Some.
+func (s Secret) Equal(other Secret) bool {
+	val, ok := s.Get()
+	otherVal, otherOk := other.Get()
+	if !ok || !otherOk {
+		return ok == otherOk
+	}
+	return secretsEqual([]byte(val), []byte(otherVal))
+}
+
+// Clear drops both the value and any reference.
+func (s *Secret) Clear() {
+	s.ref = nil
+	s.Str.Clear()
+}
+
+// Default replaces a reference which does not resolve, the same as it replaces None.
+func (s *Secret) Default(value string) (replaced bool) {
+	if s.IsSome() {
+		return false
+	}
+	s.ref = nil
+	return s.Str.Default(value)
+}
+
+// Replace drops any reference and stores value in its place.
+func (s *Secret) Replace(value string) Optional[string] {
+	old := s.Clone()
+	s.ref = nil
+	s.Str.Replace(value)
+	return old
+}
+
+func (s *Secret) Transform(t Transformer[string]) error {
+	if s.IsNone() {
+		return nil
+	}
+
+	val, err := s.Resolve()
+	if err != nil {
+		return err
+	}
+	tmp, err := t(val)
+	if err != nil {
+		return err
+	}
+	s.Replace(tmp)
+	return nil
+}
+
+func (s Secret) secretCipher() SecretCipher {
+	if s.cipher != nil {
+		return s.cipher
+	}
+	return DefaultSecretCipher()
 }
 
 // Type overrides the Type() method from the inner string. Part of the flag.Value interface.
@@ -58,31 +259,152 @@ func (s Secret) LogValue() slog.Value {
 	return slog.StringValue(s.String())
 }
 
-// Scan implements database/sql.Scanner interface.
+// Set overrides Set from the inner string so that encrypted envelopes are decrypted, and references are recognized if
+// WithReferences was used. Part of the flag.Value interface.
+func (s *Secret) Set(str string) error {
+	return s.UnmarshalText([]byte(str))
+}
+
+// MarshalText writes the reference for Secrets created from one. Otherwise the secret is only encrypted if
+// SetSecretEnvelopeMarshaling has been enabled and a SecretCipher is available, and the plaintext is written if not.
+func (s Secret) MarshalText() (text []byte, err error) {
+	if s.ref != nil {
+		return []byte(s.ref.uri), nil
+	}
+
+	val, ok := s.Get()
+	c := s.secretCipher()
+	if !ok || c == nil || !secretEnvelopeMarshaling() {
+		if err := checkPlaintext([]byte(val)); ok && err != nil {
+			return nil, err
+		}
+		return s.Str.MarshalText()
+	}
+
+	envelope, err := sealEnvelope(c, []byte(val))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(envelope), nil
+}
+
+// UnmarshalText decrypts encrypted envelopes, and stores references to be resolved later if WithReferences was used.
+// Any other text is unmarshaled the same way as Str.
+func (s *Secret) UnmarshalText(text []byte) error {
+	return s.unmarshalText(text, s.refs)
+}
+
+func (s *Secret) unmarshalText(text []byte, refs bool) error {
+	if _, ok := parseSecretRef(string(text)); ok && refs {
+		s.setRef(string(text))
+		return nil
+	}
+
+	s.ref = nil
+	if !isEnvelope(string(text)) {
+		return s.Str.UnmarshalText(text)
+	}
+
+	val, err := openEnvelope(s.secretCipher(), string(text))
+	if err != nil {
+		return err
+	}
+	_ = s.Replace(string(val))
+	return nil
+}
+
+// MarshalJSON writes references and encrypts the secret under the same conditions as MarshalText.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	if s.ref == nil && (s.IsNone() || s.secretCipher() == nil || !secretEnvelopeMarshaling()) {
+		if val, ok := s.Str.Get(); ok {
+			if err := checkPlaintext([]byte(val)); err != nil {
+				return nil, err
+			}
+		}
+		return s.Str.MarshalJSON()
+	}
+
+	text, err := s.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON handles encrypted envelopes and references the same as UnmarshalText, while any other value is
+// unmarshaled the same way as Str.
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil || isNoneString(str) {
+		s.ref = nil
+		return s.Str.UnmarshalJSON(data)
+	}
+	return s.UnmarshalText([]byte(str))
+}
+
+// MarshalTOML writes the Secret the same way as MarshalText, as a TOML string.
+func (s Secret) MarshalTOML() ([]byte, error) {
+	text, err := s.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(tomlQuote(string(text))), nil
+}
+
+// UnmarshalTOML handles TOML strings the same as UnmarshalText.
+func (s *Secret) UnmarshalTOML(data any) error {
+	if str, ok := data.(string); ok {
+		return s.UnmarshalText([]byte(str))
+	}
+	s.ref = nil
+	return s.Str.UnmarshalTOML(data)
+}
+
+// Scan implements database/sql.Scanner interface. Encrypted envelopes written by Value are decrypted, while plaintext
+// values are accepted as is so that existing rows can still be read.
 func (s *Secret) Scan(src any) error {
-	// TODO: encode with a secret key before writing to DB
 	if src == nil {
 		// NULL value row
 		s.Clear()
 		return nil
 	}
-	switch t := src.(type) {
-	case string:
-		_ = s.Replace(t)
-	case []byte:
-		_ = s.Replace(string(t))
-	default:
-		return fmt.Errorf("converting driver.Value type %T to %s", src, s.Type())
+	val, err := scanString(src)
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, s.Type(), err)
 	}
+	// Values from the database are never treated as references, since that would let anyone who can write to the
+	// table read arbitrary files and environment variables.
+	s.ref = nil
+	if isEnvelope(val) {
+		plaintext, err := openEnvelope(s.secretCipher(), val)
+		if err != nil {
+			return fmt.Errorf("decrypting %s: %w", s.Type(), err)
+		}
+		val = string(plaintext)
+	}
+	_ = s.Replace(val)
 	return nil
 }
 
-// Value implements the database/sql/driver.Valuer interface
+// Value implements the database/sql/driver.Valuer interface. If a SecretCipher is available the secret is encrypted
+// and stored as an envelope holding the key ID and ciphertext, otherwise the plaintext is stored. References are
+// resolved first, so it is always the secret that is stored rather than the reference, and a reference which does not
+// resolve is an error rather than NULL.
 func (s Secret) Value() (driver.Value, error) {
-	// TODO: decode with secret key after receiving from the DB
-	val, ok := s.Get()
-	if ok {
+	if s.ref == nil && s.Str.IsNone() {
+		return nil, nil
+	}
+	val, err := s.Resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	c := s.secretCipher()
+	if c == nil {
+		if err := checkPlaintext([]byte(val)); err != nil {
+			return nil, err
+		}
 		return val, nil
 	}
-	return nil, nil
+	return sealEnvelope(c, []byte(val))
 }
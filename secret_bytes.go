@@ -0,0 +1,312 @@
+package optional
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sync"
+)
+
+// SecretBytes is an optional secret stored in a byte slice rather than a string, so that the plaintext can be wiped
+// from memory with Destroy once it is no longer needed. Go strings are immutable and may be copied freely by the
+// runtime, which makes that impossible for Secret.
+//
+// A SecretBytes is a handle to a single buffer. Copies of a SecretBytes share the buffer, so Destroy or Set on any copy
+// is seen by all of them. The plaintext is only exposed within Use, which keeps access short and easy to audit. Like
+// Secret, a SecretBytes is always redacted when printed or logged.
+//
+// The zero value is None and ready to use.
+type SecretBytes struct {
+	buf    *secretBuffer
+	cipher SecretCipher
+}
+
+type secretBuffer struct {
+	mu   sync.RWMutex
+	b    []byte
+	some bool
+}
+
+func newSecretBuffer() *secretBuffer {
+	buf := &secretBuffer{}
+	// Wipe the plaintext even if the owner forgets to call Destroy.
+	runtime.SetFinalizer(buf, func(buf *secretBuffer) {
+		clear(buf.b)
+	})
+	return buf
+}
+
+// SomeSecretBytes creates a SecretBytes holding a copy of value, then zeroes value so that the caller's copy of the
+// secret does not outlive this one.
+func SomeSecretBytes(value []byte) SecretBytes {
+	var s SecretBytes
+	s.replace(value)
+	clear(value)
+	return s
+}
+
+func NoSecretBytes() SecretBytes {
+	return SecretBytes{}
+}
+
+// WithCipher returns a copy of the SecretBytes which uses c instead of the default SecretCipher.
+func (s SecretBytes) WithCipher(c SecretCipher) SecretBytes {
+	s.cipher = c
+	return s
+}
+
+func (s SecretBytes) secretCipher() SecretCipher {
+	if s.cipher != nil {
+		return s.cipher
+	}
+	return DefaultSecretCipher()
+}
+
+// replace copies value into the buffer, wiping whatever it held before.
+func (s *SecretBytes) replace(value []byte) {
+	if s.buf == nil {
+		s.buf = newSecretBuffer()
+	}
+
+	s.buf.mu.Lock()
+	defer s.buf.mu.Unlock()
+	clear(s.buf.b)
+	s.buf.b = append(make([]byte, 0, len(value)), value...)
+	s.buf.some = true
+}
+
+func (s SecretBytes) IsSome() bool {
+	if s.buf == nil {
+		return false
+	}
+
+	s.buf.mu.RLock()
+	defer s.buf.mu.RUnlock()
+	return s.buf.some
+}
+
+func (s SecretBytes) IsNone() bool {
+	return !s.IsSome()
+}
+
+// Use calls f with the plaintext. f must not keep a reference to the slice or modify it, since the same memory is wiped
+// by Destroy. Destroy and Set block until f returns. Use returns an error without calling f if the SecretBytes is None,
+// and otherwise returns the error from f.
+func (s SecretBytes) Use(f func([]byte) error) error {
+	if s.buf == nil {
+		return optionalError("Attempted to Use SecretBytes with None value")
+	}
+
+	s.buf.mu.RLock()
+	defer s.buf.mu.RUnlock()
+	if !s.buf.some {
+		return optionalError("Attempted to Use SecretBytes with None value")
+	}
+	return f(s.buf.b)
+}
+
+// Match compares the secret to probe in constant time, the same as Secret.Match.
+func (s SecretBytes) Match(probe []byte) bool {
+	match := false
+	_ = s.Use(func(b []byte) error {
+		match = secretsEqual(b, probe)
+		return nil
+	})
+	return match
+}
+
+// Equal compares two SecretBytes in constant time. Two None values are equal, while None is never equal to Some.
+func (s SecretBytes) Equal(other SecretBytes) bool {
+	if s.IsNone() || other.IsNone() {
+		return s.IsNone() == other.IsNone()
+	}
+	if s.buf == other.buf {
+		return true
+	}
+
+	// Only one lock is held at a time. Holding both would deadlock if another goroutine compared the same two
+	// SecretBytes in the opposite order while a writer was waiting on either buffer.
+	var theirs []byte
+	err := other.Use(func(b []byte) error {
+		theirs = append(make([]byte, 0, len(b)), b...)
+		return nil
+	})
+	defer clear(theirs)
+	return err == nil && s.Match(theirs)
+}
+
+// Destroy zeroes the buffer holding the secret and sets the value to None. This affects every copy of the SecretBytes.
+func (s *SecretBytes) Destroy() {
+	if s.buf == nil {
+		return
+	}
+
+	s.buf.mu.Lock()
+	defer s.buf.mu.Unlock()
+	clear(s.buf.b)
+	s.buf.b = nil
+	s.buf.some = false
+}
+
+// Clear is an alias for Destroy.
+func (s *SecretBytes) Clear() {
+	s.Destroy()
+}
+
+func (s SecretBytes) Type() string {
+	return "SecretBytes"
+}
+
+// String ALWAYS redact secrets
+func (s SecretBytes) String() string {
+	return "***REDACTED***"
+}
+
+// Format ALWAYS redact secrets no matter what formatting verb or flag is set
+func (s SecretBytes) Format(f fmt.State, verb rune) {
+	_, _ = f.Write([]byte(s.String()))
+}
+
+// LogValue redacts secrets when logging as well.
+func (s SecretBytes) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// Set implements the flag.Value interface. Note that str itself is an immutable string which can not be wiped.
+func (s *SecretBytes) Set(str string) error {
+	return s.UnmarshalText([]byte(str))
+}
+
+// MarshalText returns a copy of the plaintext, which the caller is responsible for wiping. If
+// SetSecretEnvelopeMarshaling has been enabled and a SecretCipher is available, the secret is encrypted instead, the
+// same as Secret.
+func (s SecretBytes) MarshalText() (text []byte, err error) {
+	if s.IsNone() {
+		return []byte("None"), nil
+	}
+
+	c := s.secretCipher()
+	err = s.Use(func(b []byte) error {
+		if c == nil || !secretEnvelopeMarshaling() {
+			if err := checkPlaintext(b); err != nil {
+				return err
+			}
+			text = append(make([]byte, 0, len(b)), b...)
+			return nil
+		}
+
+		envelope, err := sealEnvelope(c, b)
+		text = []byte(envelope)
+		return err
+	})
+	return text, err
+}
+
+// UnmarshalText copies text into the SecretBytes, decrypting it first if it is an encrypted envelope. The strings
+// "None", "none", "null", and "nil" destroy the secret instead.
+func (s *SecretBytes) UnmarshalText(text []byte) error {
+	if isNoneString(string(text)) {
+		s.Destroy()
+		return nil
+	}
+	if !isEnvelope(string(text)) {
+		s.replace(text)
+		return nil
+	}
+
+	plaintext, err := openEnvelope(s.secretCipher(), string(text))
+	if err != nil {
+		return err
+	}
+	s.replace(plaintext)
+	clear(plaintext)
+	return nil
+}
+
+// MarshalJSON writes the secret as a json string, or null if it is None.
+func (s SecretBytes) MarshalJSON() ([]byte, error) {
+	if s.IsNone() {
+		return json.Marshal(nil)
+	}
+
+	text, err := s.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	defer clear(text)
+	return json.Marshal(string(text))
+}
+
+func (s *SecretBytes) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		s.Destroy()
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	return s.UnmarshalText([]byte(str))
+}
+
+// Scan implements database/sql.Scanner interface. The value is copied out of the driver's buffer and decrypted if it is
+// an encrypted envelope, the same as Secret.
+func (s *SecretBytes) Scan(src any) error {
+	switch t := src.(type) {
+	case nil:
+		// NULL value row
+		s.Destroy()
+		return nil
+	case []byte:
+		if !isEnvelope(string(t)) {
+			s.replace(t)
+			return nil
+		}
+		src = string(t)
+	}
+
+	val, err := scanString(src)
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, s.Type(), err)
+	}
+	if !isEnvelope(val) {
+		s.replace([]byte(val))
+		return nil
+	}
+
+	plaintext, err := openEnvelope(s.secretCipher(), val)
+	if err != nil {
+		return fmt.Errorf("decrypting %s: %w", s.Type(), err)
+	}
+	s.replace(plaintext)
+	clear(plaintext)
+	return nil
+}
+
+// Value implements the database/sql/driver.Valuer interface. If a SecretCipher is available the secret is stored as an
+// encrypted envelope, otherwise a copy of the plaintext is stored as []byte.
+func (s SecretBytes) Value() (driver.Value, error) {
+	if s.IsNone() {
+		return nil, nil
+	}
+
+	var val driver.Value
+	c := s.secretCipher()
+	err := s.Use(func(b []byte) error {
+		if c == nil {
+			if err := checkPlaintext(b); err != nil {
+				return err
+			}
+			val = append(make([]byte, 0, len(b)), b...)
+			return nil
+		}
+
+		envelope, err := sealEnvelope(c, b)
+		val = envelope
+		return err
+	})
+	return val, err
+}
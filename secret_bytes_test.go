@@ -0,0 +1,153 @@
+package optional_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/brnsampson/optional"
+	"gotest.tools/v3/assert"
+)
+
+func TestSecretBytesRedacted(t *testing.T) {
+	s := optional.SomeSecretBytes([]byte("hunter2"))
+	assert.Equal(t, "SecretBytes", s.Type())
+	assert.Equal(t, "***REDACTED***", s.String())
+	assert.Equal(t, "***REDACTED***", fmt.Sprintf("%v", s))
+	assert.Assert(t, !strings.Contains(fmt.Sprintf("%v %s %x %#v %q", s, s, s, s, s), "hunter2"))
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("test", "secret", s)
+	assert.Assert(t, !strings.Contains(buf.String(), "hunter2"))
+	assert.Assert(t, strings.Contains(buf.String(), "***REDACTED***"))
+}
+
+func TestSecretBytesWipesSource(t *testing.T) {
+	src := []byte("hunter2")
+	s := optional.SomeSecretBytes(src)
+	assert.DeepEqual(t, make([]byte, 7), src)
+
+	err := s.Use(func(b []byte) error {
+		assert.Equal(t, "hunter2", string(b))
+		return nil
+	})
+	assert.NilError(t, err)
+}
+
+func TestSecretBytesDestroy(t *testing.T) {
+	s := optional.SomeSecretBytes([]byte("hunter2"))
+	copied := s
+
+	var leaked []byte
+	assert.NilError(t, s.Use(func(b []byte) error {
+		// Deliberately break the rules to check that the memory really is wiped
+		leaked = b
+		return nil
+	}))
+
+	s.Destroy()
+	assert.Assert(t, s.IsNone())
+	assert.Assert(t, copied.IsNone())
+	assert.DeepEqual(t, make([]byte, 7), leaked)
+
+	err := s.Use(func(b []byte) error {
+		t.Fatal("Use should not call f for None")
+		return nil
+	})
+	assert.Assert(t, err != nil)
+
+	// Destroying twice, or destroying the zero value, is harmless
+	s.Destroy()
+	var zero optional.SecretBytes
+	zero.Destroy()
+	assert.Assert(t, zero.IsNone())
+}
+
+func TestSecretBytesUseError(t *testing.T) {
+	s := optional.SomeSecretBytes([]byte("hunter2"))
+	expected := errors.New("oops")
+	err := s.Use(func([]byte) error { return expected })
+	assert.Assert(t, errors.Is(err, expected))
+}
+
+func TestSecretBytesSetWipesOld(t *testing.T) {
+	var s optional.SecretBytes
+	assert.NilError(t, s.Set("first"))
+
+	var old []byte
+	assert.NilError(t, s.Use(func(b []byte) error {
+		old = b
+		return nil
+	}))
+
+	assert.NilError(t, s.Set("second"))
+	assert.DeepEqual(t, make([]byte, 5), old)
+
+	assert.NilError(t, s.Set("None"))
+	assert.Assert(t, s.IsNone())
+}
+
+func TestSecretBytesJSON(t *testing.T) {
+	type config struct {
+		Key optional.SecretBytes `json:"key"`
+	}
+
+	var cfg config
+	assert.NilError(t, json.Unmarshal([]byte(`{"key":"hunter2"}`), &cfg))
+	assert.Assert(t, cfg.Key.IsSome())
+
+	data, err := json.Marshal(cfg)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"key":"hunter2"}`, string(data))
+
+	assert.NilError(t, json.Unmarshal([]byte(`{"key":null}`), &cfg))
+	assert.Assert(t, cfg.Key.IsNone())
+
+	data, err = json.Marshal(cfg)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"key":null}`, string(data))
+}
+
+func TestSecretBytesSql(t *testing.T) {
+	s := optional.SomeSecretBytes([]byte("hunter2"))
+	val, err := s.Value()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []byte("hunter2"), val)
+
+	var out optional.SecretBytes
+	driverBuf := []byte("hunter2")
+	assert.NilError(t, out.Scan(driverBuf))
+	// The scanned value must not alias the driver's buffer
+	copy(driverBuf, "xxxxxxx")
+	assert.NilError(t, out.Use(func(b []byte) error {
+		assert.Equal(t, "hunter2", string(b))
+		return nil
+	}))
+
+	assert.NilError(t, out.Scan(nil))
+	assert.Assert(t, out.IsNone())
+	val, err = out.Value()
+	assert.NilError(t, err)
+	assert.Assert(t, val == nil)
+}
+
+func TestSecretBytesEncrypted(t *testing.T) {
+	kr, err := optional.NewKeyring("k1", testKey1)
+	assert.NilError(t, err)
+
+	s := optional.SomeSecretBytes([]byte("hunter2")).WithCipher(kr)
+	val, err := s.Value()
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(val.(string), "enc:v1:k1:"))
+
+	out := optional.NoSecretBytes().WithCipher(kr)
+	assert.NilError(t, out.Scan([]byte(val.(string))))
+	assert.NilError(t, out.Use(func(b []byte) error {
+		assert.Equal(t, "hunter2", string(b))
+		return nil
+	}))
+}
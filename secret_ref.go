@@ -0,0 +1,160 @@
+package optional
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SecretProvider resolves secret references with a particular URI scheme, such as vault://path/to/secret, into the
+// secret they point to. Providers are registered with RegisterSecretProvider.
+type SecretProvider interface {
+	Resolve(ref *url.URL) (string, error)
+}
+
+// SecretProviderFunc adapts an ordinary function into a SecretProvider.
+type SecretProviderFunc func(ref *url.URL) (string, error)
+
+func (f SecretProviderFunc) Resolve(ref *url.URL) (string, error) {
+	return f(ref)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]SecretProvider{
+		"file": FileSecretProvider{},
+		"env":  SecretProviderFunc(resolveEnvRef),
+	}
+)
+
+// RegisterSecretProvider makes p responsible for resolving every secret reference with the given scheme. Registering a
+// provider for a scheme which already has one replaces it, which includes the built in "file" and "env" providers.
+// Passing a nil provider unregisters the scheme, after which values using it are treated as literal secrets again.
+func RegisterSecretProvider(scheme string, p SecretProvider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	scheme = strings.ToLower(scheme)
+	if p == nil {
+		delete(providers, scheme)
+	} else {
+		providers[scheme] = p
+	}
+}
+
+func lookupSecretProvider(scheme string) (SecretProvider, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	p, ok := providers[strings.ToLower(scheme)]
+	return p, ok
+}
+
+// parseSecretRef returns the parsed reference if s uses the scheme of a registered SecretProvider, e.g. file:///path or
+// env:VAR. Anything else is a literal secret.
+func parseSecretRef(s string) (*url.URL, bool) {
+	scheme, _, ok := strings.Cut(s, ":")
+	if !ok || scheme == "" {
+		return nil, false
+	}
+	if _, ok := lookupSecretProvider(scheme); !ok {
+		return nil, false
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, false
+	}
+	return u, true
+}
+
+// fileSecretRef builds a file reference for path which the FileSecretProvider can resolve.
+func fileSecretRef(path string) string {
+	if filepath.IsAbs(path) {
+		u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+		return u.String()
+	}
+	return "file:" + filepath.ToSlash(path)
+}
+
+// secretRef is shared between copies of a Secret so that a reference is only resolved once no matter how many times
+// the Secret is copied. Only a successful resolution is cached, so a transient error such as a secret file which has
+// not been mounted yet is retried the next time the secret is needed.
+type secretRef struct {
+	uri      string
+	mu       sync.Mutex
+	val      string
+	resolved bool
+}
+
+func (r *secretRef) resolve() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.resolved {
+		return r.val, nil
+	}
+
+	u, ok := parseSecretRef(r.uri)
+	if !ok {
+		return "", fmt.Errorf("no secret provider registered for %q", r.uri)
+	}
+	p, _ := lookupSecretProvider(u.Scheme)
+	val, err := p.Resolve(u)
+	if err != nil {
+		return "", fmt.Errorf("resolving secret %s: %w", r.uri, err)
+	}
+	r.val, r.resolved = val, true
+	return val, nil
+}
+
+// FileSecretProvider resolves file references by reading the secret from disk, which is how secrets are usually mounted
+// into containers, e.g. file:///run/secrets/db_password. A single trailing newline is trimmed from the file contents.
+// Relative paths such as file:db_password are resolved against Dir, or the working directory if Dir is empty.
+//
+// A FileSecretProvider with an empty Dir is registered for the "file" scheme by default.
+type FileSecretProvider struct {
+	Dir string
+}
+
+func (p FileSecretProvider) Resolve(ref *url.URL) (string, error) {
+	if ref.Host != "" && ref.Host != "localhost" {
+		return "", fmt.Errorf("file secret references must be local, got host %q", ref.Host)
+	}
+
+	path := ref.Path
+	if path == "" {
+		path = ref.Opaque
+	}
+	if path == "" {
+		return "", optionalError("file secret reference has no path")
+	}
+
+	path = filepath.FromSlash(path)
+	if !filepath.IsAbs(path) && p.Dir != "" {
+		path = filepath.Join(p.Dir, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	secret := strings.TrimSuffix(string(data), "\n")
+	return strings.TrimSuffix(secret, "\r"), nil
+}
+
+// resolveEnvRef resolves env:VAR references by looking up VAR in the environment when the secret is first used.
+func resolveEnvRef(ref *url.URL) (string, error) {
+	name := ref.Opaque
+	if name == "" {
+		name = ref.Host
+	}
+
+	val, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return val, nil
+}
@@ -0,0 +1,208 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/brnsampson/optional"
+	"go-simpler.org/env"
+	"gotest.tools/v3/assert"
+)
+
+func TestSecretRefFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db_password")
+	assert.NilError(t, os.WriteFile(path, []byte("hunter2\n"), 0o600))
+	ref := (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+
+	s := optional.NoSecret().WithReferences()
+	assert.NilError(t, s.Set(ref))
+	assert.Assert(t, s.IsSome())
+	assert.Equal(t, "***REDACTED***", s.String())
+
+	val, ok := s.Get()
+	assert.Assert(t, ok)
+	assert.Equal(t, "hunter2", val)
+
+	// The reference, not the secret, is marshaled
+	text, err := s.MarshalText()
+	assert.NilError(t, err)
+	assert.Equal(t, ref, string(text))
+
+	data, err := json.Marshal(s)
+	assert.NilError(t, err)
+	assert.Equal(t, `"`+ref+`"`, string(data))
+
+	out := optional.NoSecret().WithReferences()
+	assert.NilError(t, json.Unmarshal(data, &out))
+	got, ok := out.Reference()
+	assert.Assert(t, ok)
+	assert.Equal(t, ref, got)
+
+	// Replacing the value drops the reference
+	s.Replace("new")
+	_, ok = s.Reference()
+	assert.Assert(t, !ok)
+	assert.Assert(t, s.Match("new"))
+}
+
+func TestSecretRefRelativeFile(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("abc"), 0o600))
+
+	p := optional.FileSecretProvider{Dir: dir}
+	u, err := url.Parse("file:token")
+	assert.NilError(t, err)
+
+	val, err := p.Resolve(u)
+	assert.NilError(t, err)
+	assert.Equal(t, "abc", val)
+
+	u, err = url.Parse("file://remote/token")
+	assert.NilError(t, err)
+	_, err = p.Resolve(u)
+	assert.ErrorContains(t, err, "remote")
+}
+
+func TestSecretRefEnvIsLazy(t *testing.T) {
+	s, err := optional.SecretRef("env:OPTIONAL_TEST_SECRET")
+	assert.NilError(t, err)
+
+	// The variable is only read when the secret is first used
+	t.Setenv("OPTIONAL_TEST_SECRET", "hunter2")
+	assert.Assert(t, s.Match("hunter2"))
+
+	// Once resolved, the result is cached
+	t.Setenv("OPTIONAL_TEST_SECRET", "changed")
+	assert.Assert(t, s.Match("hunter2"))
+
+	// A reference which can't be resolved is None, but it is still configured so it is not zero
+	missing, err := optional.SecretRef("env:OPTIONAL_TEST_MISSING")
+	assert.NilError(t, err)
+	assert.Assert(t, missing.IsNone())
+	_, ok := missing.Get()
+	assert.Assert(t, !ok)
+	assert.Assert(t, !missing.IsZero())
+	_, err = missing.Resolve()
+	assert.ErrorContains(t, err, "OPTIONAL_TEST_MISSING")
+
+	val, err := missing.Value()
+	assert.Assert(t, err != nil)
+	assert.Assert(t, val == nil)
+
+	assert.Assert(t, missing.Default("fallback"))
+	assert.Assert(t, missing.Match("fallback"))
+}
+
+func TestSecretRefRetriesFailures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	s, err := optional.SecretRef((&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String())
+	assert.NilError(t, err)
+
+	// The file hasn't been mounted yet
+	_, err = s.Resolve()
+	assert.Assert(t, err != nil)
+	assert.Assert(t, s.IsNone())
+
+	// A later attempt succeeds, and is shared with copies made before it
+	cp := s
+	assert.NilError(t, os.WriteFile(path, []byte("hunter2"), 0o600))
+	val, err := s.Resolve()
+	assert.NilError(t, err)
+	assert.Equal(t, "hunter2", val)
+	assert.NilError(t, os.Remove(path))
+	assert.Assert(t, cp.Match("hunter2"))
+}
+
+func TestSecretRefProvider(t *testing.T) {
+	calls := 0
+	optional.RegisterSecretProvider("vault", optional.SecretProviderFunc(func(ref *url.URL) (string, error) {
+		calls++
+		if ref.Host != "kv" {
+			return "", errors.New("unknown mount")
+		}
+		return "from" + ref.Path, nil
+	}))
+	defer optional.RegisterSecretProvider("vault", nil)
+
+	s := optional.NoSecret().WithReferences()
+	assert.NilError(t, s.UnmarshalText([]byte("vault://kv/db")))
+	assert.Equal(t, 0, calls)
+
+	copied := s
+	assert.Assert(t, s.Match("from/db"))
+	assert.Assert(t, copied.Match("from/db"))
+	assert.Equal(t, 1, calls)
+
+	_, err := optional.SecretRef("unregistered://kv/db")
+	assert.Assert(t, err != nil)
+
+	// Unregistered schemes are treated as literal secrets
+	assert.NilError(t, s.Set("unregistered://kv/db"))
+	assert.Assert(t, s.Match("unregistered://kv/db"))
+}
+
+func TestSecretRefsAreOptIn(t *testing.T) {
+	type config struct {
+		Token optional.Secret `json:"token" toml:"token" env:"TOKEN"`
+	}
+
+	// Untrusted input can't turn a Secret into a reference to a file or another variable
+	var c config
+	assert.NilError(t, json.Unmarshal([]byte(`{"token":"file:///etc/shadow"}`), &c))
+	_, ok := c.Token.Reference()
+	assert.Assert(t, !ok)
+	assert.Assert(t, c.Token.Match("file:///etc/shadow"))
+
+	_, err := toml.Decode(`token = "env:HOME"`, &c)
+	assert.NilError(t, err)
+	assert.Assert(t, c.Token.Match("env:HOME"))
+
+	assert.NilError(t, c.Token.Set("env:HOME"))
+	assert.Assert(t, c.Token.Match("env:HOME"))
+
+	assert.NilError(t, optional.LoadEnv(&c, optional.WithEnvSource(env.Map{"TOKEN": "env:HOME"})))
+	assert.Assert(t, c.Token.Match("env:HOME"))
+
+	// Unless the caller opts in
+	t.Setenv("OPTIONAL_TEST_SECRET", "hunter2")
+	c = config{Token: optional.NoSecret().WithReferences()}
+	assert.NilError(t, json.Unmarshal([]byte(`{"token":"env:OPTIONAL_TEST_SECRET"}`), &c))
+	assert.Assert(t, c.Token.Match("hunter2"))
+
+	c = config{}
+	vars := env.Map{"TOKEN": "env:OPTIONAL_TEST_SECRET"}
+	assert.NilError(t, optional.LoadEnv(&c, optional.WithEnvSource(vars), optional.WithEnvSecretReferences()))
+	assert.Assert(t, c.Token.Match("hunter2"))
+}
+
+func TestSecretScanIgnoresRefs(t *testing.T) {
+	s := optional.NoSecret()
+	assert.NilError(t, s.Scan("env:HOME"))
+	_, ok := s.Reference()
+	assert.Assert(t, !ok)
+	assert.Assert(t, s.Match("env:HOME"))
+}
+
+func TestSecretRefAll(t *testing.T) {
+	t.Setenv("OPTIONAL_TEST_SECRET", "hunter2")
+	s, err := optional.SecretRef("env:OPTIONAL_TEST_SECRET")
+	assert.NilError(t, err)
+
+	// Ranging over a reference resolves it, the same as Get
+	var vals []string
+	for val := range s.All() {
+		vals = append(vals, val)
+	}
+	assert.DeepEqual(t, []string{"hunter2"}, vals)
+
+	missing, err := optional.SecretRef("env:OPTIONAL_TEST_MISSING")
+	assert.NilError(t, err)
+	for range missing.All() {
+		t.Fatal("unresolvable reference yielded a value")
+	}
+}
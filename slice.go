@@ -0,0 +1,386 @@
+package optional
+
+import (
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Formats used by Slice.Value to store slices in a database. An empty format is the same as SLICE_JSON_FORMAT.
+const (
+	// SLICE_JSON_FORMAT stores slices as JSON arrays, which works with any text or JSON column.
+	SLICE_JSON_FORMAT string = "json"
+	// SLICE_POSTGRES_FORMAT stores slices as Postgres array literals such as {a,b,"c d"}, for array columns.
+	SLICE_POSTGRES_FORMAT string = "postgres"
+)
+
+// Slice is an optional list of values, built on AnyOption. An explicitly empty list is Some and is kept distinct from
+// None by every encoding: it is written as "" in text, [] in JSON, and as an empty array in SQL, while None is written
+// as "None", null, and NULL respectively.
+//
+// Elements may be strings, numbers, bools, time.Duration, or any type with an UnmarshalText method, which includes
+// every LoadableOptional such as Int or Time. As with AnyOption, Set splits its input on commas and appends to the
+// current value, so both repeated flags and comma separated env vars work. A default held by the field is replaced by
+// the first use of a flag bound with BindFlags, and by LoadEnv.
+//
+// Scan accepts JSON arrays as well as Postgres array literals, which is how Postgres drivers hand array columns to
+// database/sql. Unquoted NULL elements are only accepted when the element type can hold None. Value writes whichever
+// of the two is chosen by DataFormat.
+type Slice[T any] struct {
+	AnyOption[[]T]
+	// DataFormat is one of the SLICE_*_FORMAT constants, used by Value.
+	DataFormat string
+}
+
+// Strs is an optional list of strings.
+type Strs = Slice[string]
+
+// Ints is an optional list of ints.
+type Ints = Slice[int]
+
+// Durations is an optional list of durations. Elements are parsed the same as Duration, so units such as d and w and
+// ISO 8601 durations are accepted, and they are written to JSON as strings rather than as a number of nanoseconds.
+type Durations = Slice[time.Duration]
+
+// SomeSlice returns a Slice holding values. Calling it with no values gives an empty list, not None.
+func SomeSlice[T any](values ...T) Slice[T] {
+	if values == nil {
+		values = []T{}
+	}
+	return Slice[T]{AnyOption: SomeAny(values)}
+}
+
+func NoSlice[T any]() Slice[T] {
+	return Slice[T]{AnyOption: NoneAny[[]T]()}
+}
+
+func SomeStrs(values ...string) Strs {
+	return SomeSlice(values...)
+}
+
+func NoStrs() Strs {
+	return NoSlice[string]()
+}
+
+func SomeInts(values ...int) Ints {
+	return SomeSlice(values...)
+}
+
+func NoInts() Ints {
+	return NoSlice[int]()
+}
+
+func SomeDurations(values ...time.Duration) Durations {
+	return SomeSlice(values...)
+}
+
+func NoDurations() Durations {
+	return NoSlice[time.Duration]()
+}
+
+// WithFormat returns a copy of the Slice which Value writes in format.
+func (o Slice[T]) WithFormat(format string) Slice[T] {
+	o.DataFormat = format
+	return o
+}
+
+// MarshalJSON writes the Slice as a JSON array, or null for None.
+func (o Slice[T]) MarshalJSON() ([]byte, error) {
+	vals, ok := o.Get()
+	if !ok {
+		return json.Marshal(nil)
+	}
+	if vals == nil {
+		// json.Marshal writes nil slices as null, which would turn an empty list into None.
+		return []byte("[]"), nil
+	}
+
+	if isDurationSlice[T]() {
+		strs := make([]string, len(vals))
+		for i, val := range vals {
+			strs[i] = any(val).(time.Duration).String()
+		}
+		return json.Marshal(strs)
+	}
+	return json.Marshal(vals)
+}
+
+// UnmarshalJSON implements encoding/json.Unmarshaller interface
+func (o *Slice[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		o.Clear()
+		return nil
+	}
+	if !isDurationSlice[T]() {
+		return o.AnyOption.UnmarshalJSON(data)
+	}
+
+	// Durations may be given as strings such as "1m30s" or as a number of nanoseconds.
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return err
+	}
+	vals := make([]T, len(raws))
+	for i, raw := range raws {
+		var d time.Duration
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			if d, err = parseDuration(s); err != nil {
+				return err
+			}
+		} else if err := json.Unmarshal(raw, (*int64)(&d)); err != nil {
+			return fmt.Errorf("cannot unmarshal %s into a duration", raw)
+		}
+		vals[i] = any(d).(T)
+	}
+	o.Replace(vals)
+	return nil
+}
+
+// Scan implements database/sql.Scanner interface. JSON arrays and Postgres array literals are both accepted.
+func (o *Slice[T]) Scan(src any) error {
+	if src == nil {
+		// NULL value row
+		o.Clear()
+		return nil
+	}
+
+	var text string
+	switch t := src.(type) {
+	case string:
+		text = t
+	case []byte:
+		text = string(t)
+	default:
+		return fmt.Errorf("converting driver.Value type %T to %s", src, o.Type())
+	}
+
+	var err error
+	text = strings.TrimSpace(text)
+	switch {
+	case strings.HasPrefix(text, "["):
+		err = o.UnmarshalJSON([]byte(text))
+	case strings.HasPrefix(text, "{"):
+		err = o.scanPostgresArray(text)
+	default:
+		err = optionalError("expected a JSON array or a Postgres array literal")
+	}
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
+	}
+	return nil
+}
+
+func (o *Slice[T]) scanPostgresArray(text string) error {
+	elems, err := parsePostgresArray(text)
+	if err != nil {
+		return err
+	}
+
+	vals := make([]T, len(elems))
+	for i, elem := range elems {
+		v := reflect.ValueOf(&vals[i]).Elem()
+		if elem.null {
+			u, ok := v.Addr().Interface().(encoding.TextUnmarshaler)
+			if !ok {
+				return fmt.Errorf("NULL array element can not be stored in %s", v.Type())
+			}
+			if err := u.UnmarshalText([]byte("null")); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := unmarshalAnyText(v, elem.text); err != nil {
+			return err
+		}
+	}
+	o.Replace(vals)
+	return nil
+}
+
+// Value implements the database/sql/driver.Valuer interface
+func (o Slice[T]) Value() (driver.Value, error) {
+	vals, ok := o.Get()
+	if !ok {
+		return nil, nil
+	}
+	if o.DataFormat != SLICE_POSTGRES_FORMAT {
+		data, err := o.MarshalJSON()
+		return string(data), err
+	}
+
+	elems := make([]string, len(vals))
+	for i := range vals {
+		v := reflect.ValueOf(&vals[i]).Elem()
+		if opt, ok := v.Interface().(someable); ok && !opt.IsSome() {
+			elems[i] = "NULL"
+			continue
+		}
+		text, err := marshalAnyText(v)
+		if err != nil {
+			return nil, err
+		}
+		elems[i] = quotePostgresArrayElement(text)
+	}
+	return "{" + strings.Join(elems, ",") + "}", nil
+}
+
+func isDurationSlice[T any]() bool {
+	var tmp T
+	return reflect.TypeOf(&tmp).Elem() == durationType
+}
+
+// StrMap is an optional map of strings. As text, which is used by Set for flags and env vars, it is written as comma
+// separated key=value pairs, and repeated flags are merged together. In JSON and SQL it is a JSON object. As with
+// Slice, an empty map is Some and is kept distinct from None.
+type StrMap struct {
+	AnyOption[map[string]string]
+}
+
+// SomeStrMap returns a StrMap holding m. A nil map gives an empty map, not None.
+func SomeStrMap(m map[string]string) StrMap {
+	if m == nil {
+		m = map[string]string{}
+	}
+	return StrMap{SomeAny(m)}
+}
+
+func NoStrMap() StrMap {
+	return StrMap{NoneAny[map[string]string]()}
+}
+
+func (o StrMap) Type() string {
+	return "StrMap"
+}
+
+// MarshalJSON writes the StrMap as a JSON object, or null for None.
+func (o StrMap) MarshalJSON() ([]byte, error) {
+	m, ok := o.Get()
+	if !ok {
+		return json.Marshal(nil)
+	}
+	if m == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(m)
+}
+
+// Scan implements database/sql.Scanner interface. The value must be a JSON object.
+func (o *StrMap) Scan(src any) error {
+	if src == nil {
+		// NULL value row
+		o.Clear()
+		return nil
+	}
+
+	var data []byte
+	switch t := src.(type) {
+	case string:
+		data = []byte(t)
+	case []byte:
+		data = t
+	default:
+		return fmt.Errorf("converting driver.Value type %T to %s", src, o.Type())
+	}
+	if err := o.UnmarshalJSON(data); err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
+	}
+	return nil
+}
+
+// Value implements the database/sql/driver.Valuer interface
+func (o StrMap) Value() (driver.Value, error) {
+	if o.IsNone() {
+		return nil, nil
+	}
+	data, err := o.MarshalJSON()
+	return string(data), err
+}
+
+type postgresArrayElement struct {
+	text string
+	null bool
+}
+
+// parsePostgresArray parses a one dimensional Postgres array literal such as {a,"b c",NULL}.
+func parsePostgresArray(s string) ([]postgresArrayElement, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, fmt.Errorf("invalid Postgres array %q", s)
+	}
+	inner := s[1 : len(s)-1]
+	elems := []postgresArrayElement{}
+	if strings.TrimSpace(inner) == "" {
+		return elems, nil
+	}
+
+	i := 0
+	for {
+		for i < len(inner) && unicode.IsSpace(rune(inner[i])) {
+			i++
+		}
+		if i < len(inner) && inner[i] == '{' {
+			return nil, optionalError("multidimensional Postgres arrays are not supported")
+		}
+
+		var elem postgresArrayElement
+		if i < len(inner) && inner[i] == '"' {
+			var b strings.Builder
+			i++
+			for ; i < len(inner) && inner[i] != '"'; i++ {
+				if inner[i] == '\\' {
+					i++
+					if i == len(inner) {
+						break
+					}
+				}
+				b.WriteByte(inner[i])
+			}
+			if i >= len(inner) {
+				return nil, fmt.Errorf("unterminated quoted element in Postgres array %q", s)
+			}
+			i++
+			elem.text = b.String()
+			for i < len(inner) && unicode.IsSpace(rune(inner[i])) {
+				i++
+			}
+		} else {
+			end := strings.IndexByte(inner[i:], ',')
+			if end < 0 {
+				end = len(inner) - i
+			}
+			elem.text = strings.TrimSpace(inner[i : i+end])
+			elem.null = strings.EqualFold(elem.text, "NULL")
+			if elem.text == "" || strings.ContainsAny(elem.text, `{}"`) {
+				return nil, fmt.Errorf("invalid element in Postgres array %q", s)
+			}
+			i += end
+		}
+		elems = append(elems, elem)
+
+		if i == len(inner) {
+			return elems, nil
+		}
+		if inner[i] != ',' {
+			return nil, fmt.Errorf("invalid Postgres array %q", s)
+		}
+		i++
+	}
+}
+
+// quotePostgresArrayElement quotes text if it would otherwise be misread as part of an array literal.
+func quotePostgresArrayElement(text string) string {
+	needsQuotes := text == "" || strings.EqualFold(text, "NULL") || strings.ContainsAny(text, `{},"\`) ||
+		strings.IndexFunc(text, unicode.IsSpace) >= 0
+	if !needsQuotes {
+		return text
+	}
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	text = strings.ReplaceAll(text, `"`, `\"`)
+	return `"` + text + `"`
+}
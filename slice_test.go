@@ -0,0 +1,288 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/brnsampson/optional"
+	"go-simpler.org/env"
+	"gotest.tools/v3/assert"
+)
+
+func TestSliceIsLoadable(t *testing.T) {
+	// The real test is if we get compiler errors because these do not implement the interfaces used for loading
+	var _ flag.Value = &optional.Strs{}
+	var _ json.Unmarshaler = &optional.Ints{}
+	var _ json.Marshaler = optional.StrMap{}
+}
+
+func TestSliceEmptyIsNotNone(t *testing.T) {
+	empty := optional.SomeStrs()
+	none := optional.NoStrs()
+	assert.Assert(t, empty.IsSome())
+	assert.Assert(t, none.IsNone())
+
+	data, err := json.Marshal(empty)
+	assert.NilError(t, err)
+	assert.Equal(t, "[]", string(data))
+	data, err = json.Marshal(none)
+	assert.NilError(t, err)
+	assert.Equal(t, "null", string(data))
+
+	// A nil slice held in Some is still an empty list
+	data, err = json.Marshal(optional.Slice[string]{AnyOption: optional.SomeAny[[]string](nil)})
+	assert.NilError(t, err)
+	assert.Equal(t, "[]", string(data))
+
+	text, err := empty.MarshalText()
+	assert.NilError(t, err)
+	assert.Equal(t, "", string(text))
+	text, err = none.MarshalText()
+	assert.NilError(t, err)
+	assert.Equal(t, "None", string(text))
+
+	var back optional.Strs
+	assert.NilError(t, json.Unmarshal([]byte("[]"), &back))
+	assert.Assert(t, back.IsSome())
+	assert.Equal(t, 0, len(back.MustGet()))
+	assert.NilError(t, back.Set(""))
+	assert.Assert(t, back.IsSome())
+
+	val, err := empty.Value()
+	assert.NilError(t, err)
+	assert.Equal(t, "[]", val)
+	val, err = empty.WithFormat(optional.SLICE_POSTGRES_FORMAT).Value()
+	assert.NilError(t, err)
+	assert.Equal(t, "{}", val)
+	val, err = none.Value()
+	assert.NilError(t, err)
+	assert.Assert(t, val == nil)
+
+	assert.NilError(t, back.Scan("{}"))
+	assert.Assert(t, back.IsSome())
+	assert.Equal(t, 0, len(back.MustGet()))
+	assert.NilError(t, back.Scan(nil))
+	assert.Assert(t, back.IsNone())
+}
+
+func TestSliceFlagsAndEnv(t *testing.T) {
+	type config struct {
+		Hosts    optional.Strs      `flag:"host" env:"HOSTS"`
+		Ports    optional.Ints      `flag:"port" env:"PORTS"`
+		Timeouts optional.Durations `flag:"timeout" env:"TIMEOUTS"`
+		Labels   optional.StrMap    `flag:"label" env:"LABELS"`
+	}
+
+	var c config
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	assert.NilError(t, optional.BindFlags(fs, &c))
+	err := fs.Parse([]string{
+		"-host", "a", "-host", "b,c",
+		"-port", "80", "-port", "443",
+		"-timeout", "1d,30s",
+		"-label", "env=prod", "-label", "team=core",
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"a", "b", "c"}, c.Hosts.MustGet())
+	assert.DeepEqual(t, []int{80, 443}, c.Ports.MustGet())
+	assert.DeepEqual(t, []time.Duration{24 * time.Hour, 30 * time.Second}, c.Timeouts.MustGet())
+	assert.DeepEqual(t, map[string]string{"env": "prod", "team": "core"}, c.Labels.MustGet())
+
+	var e config
+	vars := env.Map{"HOSTS": "x,y", "PORTS": "8080", "LABELS": "a=1,b=2"}
+	assert.NilError(t, optional.LoadEnv(&e, optional.WithEnvSource(vars)))
+	assert.DeepEqual(t, []string{"x", "y"}, e.Hosts.MustGet())
+	assert.DeepEqual(t, []int{8080}, e.Ports.MustGet())
+	assert.Assert(t, e.Timeouts.IsNone())
+	assert.DeepEqual(t, map[string]string{"a": "1", "b": "2"}, e.Labels.MustGet())
+
+	var bad config
+	assert.Assert(t, optional.LoadEnv(&bad, optional.WithEnvSource(env.Map{"PORTS": "80,http"})) != nil)
+}
+
+func TestSliceFlagsWithDefaults(t *testing.T) {
+	type config struct {
+		Hosts    optional.Strs      `flag:"host" env:"HOSTS"`
+		Ports    optional.Ints      `flag:"port" env:"PORTS"`
+		Timeouts optional.Durations `flag:"timeout" env:"TIMEOUTS"`
+		Labels   optional.StrMap    `flag:"label" env:"LABELS"`
+	}
+	defaults := func() config {
+		return config{
+			Hosts:    optional.SomeStrs("default"),
+			Ports:    optional.SomeInts(80),
+			Timeouts: optional.SomeDurations(time.Minute),
+			Labels:   optional.SomeStrMap(map[string]string{"env": "dev"}),
+		}
+	}
+
+	// The first use of each flag replaces its default and later uses add to it
+	c := defaults()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	assert.NilError(t, optional.BindFlags(fs, &c))
+	assert.NilError(t, fs.Parse([]string{"-host", "a", "-host", "b", "-port", "443", "-label", "team=core"}))
+	assert.DeepEqual(t, []string{"a", "b"}, c.Hosts.MustGet())
+	assert.DeepEqual(t, []int{443}, c.Ports.MustGet())
+	assert.DeepEqual(t, []time.Duration{time.Minute}, c.Timeouts.MustGet())
+	assert.DeepEqual(t, map[string]string{"team": "core"}, c.Labels.MustGet())
+
+	e := defaults()
+	assert.NilError(t, optional.LoadEnv(&e, optional.WithEnvSource(env.Map{"HOSTS": "x,y", "TIMEOUTS": "1s"})))
+	assert.DeepEqual(t, []string{"x", "y"}, e.Hosts.MustGet())
+	assert.DeepEqual(t, []int{80}, e.Ports.MustGet())
+	assert.DeepEqual(t, []time.Duration{time.Second}, e.Timeouts.MustGet())
+}
+
+func TestSliceJSON(t *testing.T) {
+	type config struct {
+		Hosts    optional.Strs      `json:"hosts"`
+		Timeouts optional.Durations `json:"timeouts"`
+		Labels   optional.StrMap    `json:"labels"`
+	}
+
+	c := config{
+		Hosts:    optional.SomeStrs("a", "b"),
+		Timeouts: optional.SomeDurations(90 * time.Second),
+		Labels:   optional.SomeStrMap(nil),
+	}
+	data, err := json.Marshal(c)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"hosts":["a","b"],"timeouts":["1m30s"],"labels":{}}`, string(data))
+
+	var back config
+	assert.NilError(t, json.Unmarshal([]byte(`{"hosts":null,"timeouts":["1d",1000],"labels":{"a":"b"}}`), &back))
+	assert.Assert(t, back.Hosts.IsNone())
+	assert.DeepEqual(t, []time.Duration{24 * time.Hour, time.Microsecond}, back.Timeouts.MustGet())
+	assert.DeepEqual(t, map[string]string{"a": "b"}, back.Labels.MustGet())
+
+	assert.Assert(t, json.Unmarshal([]byte(`{"timeouts":["soon"]}`), &back) != nil)
+	assert.Assert(t, json.Unmarshal([]byte(`{"timeouts":[true]}`), &back) != nil)
+}
+
+func TestSlicePostgresArray(t *testing.T) {
+	s := optional.SomeStrs("plain", "with space", `quote"d`, `back\slash`, "", "NULL", "a,b")
+	val, err := s.WithFormat(optional.SLICE_POSTGRES_FORMAT).Value()
+	assert.NilError(t, err)
+	assert.Equal(t, `{plain,"with space","quote\"d","back\\slash","","NULL","a,b"}`, val)
+
+	var back optional.Strs
+	assert.NilError(t, back.Scan([]byte(val.(string))))
+	assert.DeepEqual(t, s.MustGet(), back.MustGet())
+
+	var ints optional.Ints
+	assert.NilError(t, ints.Scan(" { 1, 2 ,3 } "))
+	assert.DeepEqual(t, []int{1, 2, 3}, ints.MustGet())
+	assert.ErrorContains(t, ints.Scan("{1,NULL}"), "NULL")
+	assert.ErrorContains(t, ints.Scan("{{1,2},{3,4}}"), "multidimensional")
+	assert.Assert(t, ints.Scan(`{"1}`) != nil)
+	assert.Assert(t, ints.Scan("{1,,2}") != nil)
+	assert.Assert(t, ints.Scan("1,2") != nil)
+	assert.Assert(t, ints.Scan(int64(1)) != nil)
+
+	// Postgres interval arrays are parsed the same as Duration
+	var durations optional.Durations
+	assert.NilError(t, durations.Scan(`{"1 day 02:00:00",00:00:01}`))
+	assert.DeepEqual(t, []time.Duration{26 * time.Hour, time.Second}, durations.MustGet())
+
+	// Elements which can hold None accept NULL
+	var opts optional.Slice[optional.Int]
+	assert.NilError(t, opts.Scan("{1,NULL,3}"))
+	assert.Equal(t, 3, len(opts.MustGet()))
+	assert.Assert(t, opts.MustGet()[1].IsNone())
+	assert.Equal(t, 3, opts.MustGet()[2].MustGet())
+
+	val, err = opts.WithFormat(optional.SLICE_POSTGRES_FORMAT).Value()
+	assert.NilError(t, err)
+	assert.Equal(t, "{1,NULL,3}", val)
+	val, err = opts.Value()
+	assert.NilError(t, err)
+	assert.Equal(t, "[1,null,3]", val)
+}
+
+func TestSliceSql(t *testing.T) {
+	ins := "INSERT INTO optionTest (val) VALUES (?)"
+	q := `SELECT * FROM optionTest WHERE val = ?`
+	test1 := optional.SomeStrs("a", "b")
+	test2 := optional.NoStrs()
+	out1 := optional.NoStrs()
+	out2 := optional.SomeStrs("x")
+
+	db, mock, err := sqlmock.New()
+	assert.NilError(t, err, "failed to open mock database connection")
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO optionTest").WithArgs(`["a","b"]`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO optionTest").WithArgs(nil).WillReturnResult(sqlmock.NewResult(2, 1))
+	r1 := sqlmock.NewRows([]string{"val"})
+	r1.AddRow([]byte(`{a,b}`))
+	mock.ExpectQuery(`SELECT (.+) FROM optionTest`).WillReturnRows(r1)
+	r2 := sqlmock.NewRows([]string{"val"})
+	r2.AddRow(nil)
+	mock.ExpectQuery(`SELECT (.+) FROM optionTest`).WillReturnRows(r2)
+
+	_, err = db.Exec(ins, test1)
+	assert.NilError(t, err, "error using mock to insert optional Some type")
+	_, err = db.Exec(ins, test2)
+	assert.NilError(t, err, "error using mock to insert optional None type")
+
+	rows1, err := db.Query(q, test1)
+	assert.NilError(t, err)
+	defer rows1.Close()
+	for rows1.Next() {
+		assert.NilError(t, rows1.Scan(&out1))
+		assert.DeepEqual(t, []string{"a", "b"}, out1.MustGet())
+	}
+
+	rows2, err := db.Query(q, test2)
+	assert.NilError(t, err)
+	defer rows2.Close()
+	for rows2.Next() {
+		assert.NilError(t, rows2.Scan(&out2))
+		assert.Assert(t, out2.IsNone(), "None case failed")
+	}
+}
+
+func TestSliceTextRoundTrip(t *testing.T) {
+	for _, strs := range []optional.Strs{optional.SomeStrs("a,b", "c"), optional.SomeStrs(""), optional.SomeStrs()} {
+		text, err := strs.MarshalText()
+		assert.NilError(t, err)
+		var back optional.Strs
+		assert.NilError(t, back.UnmarshalText(text))
+		assert.DeepEqual(t, strs.MustGet(), back.MustGet())
+	}
+
+	m := optional.SomeStrMap(map[string]string{"a": "x=y,z"})
+	text, err := m.MarshalText()
+	assert.NilError(t, err)
+	var back optional.StrMap
+	assert.NilError(t, back.UnmarshalText(text))
+	assert.DeepEqual(t, m.MustGet(), back.MustGet())
+}
+
+func TestStrMapSql(t *testing.T) {
+	m := optional.SomeStrMap(map[string]string{"b": "2", "a": "1"})
+	val, err := m.Value()
+	assert.NilError(t, err)
+	assert.Equal(t, `{"a":"1","b":"2"}`, val)
+
+	var back optional.StrMap
+	assert.NilError(t, back.Scan([]byte(val.(string))))
+	assert.DeepEqual(t, m.MustGet(), back.MustGet())
+
+	assert.NilError(t, back.Scan("{}"))
+	assert.Assert(t, back.IsSome())
+	assert.Equal(t, 0, len(back.MustGet()))
+
+	assert.NilError(t, back.Scan(nil))
+	assert.Assert(t, back.IsNone())
+	val, err = back.Value()
+	assert.NilError(t, err)
+	assert.Assert(t, val == nil)
+
+	assert.Assert(t, back.Scan(`["a"]`) != nil)
+	assert.Assert(t, back.Scan(int64(1)) != nil)
+	assert.Equal(t, "StrMap", back.Type())
+}
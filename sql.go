@@ -0,0 +1,104 @@
+package optional
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+type number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~float32 | ~float64
+}
+
+// scanNumber converts any of the types a database driver may hand to a Scanner into the numeric type N. Integers and
+// floats must fit into N without overflowing, and floats must be whole numbers to be scanned into integer types.
+// Strings and []byte are parsed, and bools are converted to 1 or 0. time.Time values can not be converted.
+func scanNumber[N number](src any) (N, error) {
+	var n N
+	if t, ok := src.(N); ok {
+		return t, nil
+	}
+
+	switch t := src.(type) {
+	case []byte:
+		src = string(t)
+	case bool:
+		if t {
+			src = int64(1)
+		} else {
+			src = int64(0)
+		}
+	case float64:
+		// The range is checked against the target kind, since uint64 holds values which overflow int64.
+		switch reflect.TypeOf(n).Kind() {
+		case reflect.Float32, reflect.Float64:
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if t != math.Trunc(t) || t < 0 || t >= math.MaxUint64 {
+				return n, fmt.Errorf("float64 %g cannot be converted to %T without losing information", t, n)
+			}
+			src = uint64(t)
+		default:
+			if t != math.Trunc(t) || t < math.MinInt64 || t >= math.MaxInt64 {
+				return n, fmt.Errorf("float64 %g cannot be converted to %T without losing information", t, n)
+			}
+			src = int64(t)
+		}
+	case time.Time:
+		return n, fmt.Errorf("time.Time cannot be converted to %T", n)
+	}
+
+	err := unmarshalNative(src, &n)
+	return n, err
+}
+
+// scanString converts any of the types a database driver may hand to a Scanner into a string.
+func scanString(src any) (string, error) {
+	switch t := src.(type) {
+	case string:
+		return t, nil
+	case []byte:
+		return string(t), nil
+	case int64:
+		return strconv.FormatInt(t, 10), nil
+	case float64:
+		return strconv.FormatFloat(t, 'g', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	case time.Time:
+		return t.Format(DEFAULT_TIME_FORMAT), nil
+	default:
+		return "", fmt.Errorf("unsupported type %T", src)
+	}
+}
+
+// scanBool converts any of the types a database driver may hand to a Scanner into a bool. Numbers must be exactly 1
+// or 0, and strings are parsed with strconv.ParseBool.
+func scanBool(src any) (bool, error) {
+	switch t := src.(type) {
+	case bool:
+		return t, nil
+	case int64:
+		return numberToBool(float64(t))
+	case float64:
+		return numberToBool(t)
+	case string:
+		return strconv.ParseBool(t)
+	case []byte:
+		return strconv.ParseBool(string(t))
+	default:
+		return false, fmt.Errorf("unsupported type %T", src)
+	}
+}
+
+func numberToBool(f float64) (bool, error) {
+	switch f {
+	case 1:
+		return true, nil
+	case 0:
+		return false, nil
+	default:
+		return false, fmt.Errorf("%g cannot be converted to bool", f)
+	}
+}
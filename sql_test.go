@@ -0,0 +1,156 @@
+package optional_test
+
+import (
+	"database/sql"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/brnsampson/optional"
+	"gotest.tools/v3/assert"
+)
+
+// The real test is if we get compiler errors because any of these do not implement StorableOptional
+var (
+	_ optional.StorableOptional[int]           = &optional.Int{}
+	_ optional.StorableOptional[int8]          = &optional.Int8{}
+	_ optional.StorableOptional[int16]         = &optional.Int16{}
+	_ optional.StorableOptional[int32]         = &optional.Int32{}
+	_ optional.StorableOptional[int64]         = &optional.Int64{}
+	_ optional.StorableOptional[uint]          = &optional.Uint{}
+	_ optional.StorableOptional[uint8]         = &optional.Uint8{}
+	_ optional.StorableOptional[uint16]        = &optional.Uint16{}
+	_ optional.StorableOptional[uint32]        = &optional.Uint32{}
+	_ optional.StorableOptional[uint64]        = &optional.Uint64{}
+	_ optional.StorableOptional[float32]       = &optional.Float32{}
+	_ optional.StorableOptional[float64]       = &optional.Float64{}
+	_ optional.StorableOptional[string]        = &optional.Str{}
+	_ optional.StorableOptional[string]        = &optional.Secret{}
+	_ optional.StorableOptional[string]        = &optional.HashedSecret{}
+	_ optional.StorableOptional[bool]          = &optional.Bool{}
+	_ optional.StorableOptional[byte]          = &optional.Byte{}
+	_ optional.StorableOptional[time.Time]     = &optional.Time{}
+	_ optional.StorableOptional[time.Duration] = &optional.Duration{}
+)
+
+// scanOne runs a query against a mock database which returns a single row holding src, then scans it into dst.
+func scanOne(t *testing.T, src any, dst sql.Scanner) error {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	assert.NilError(t, err, "failed to open mock database connection")
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"val"}).AddRow(src)
+	mock.ExpectQuery(`SELECT (.+) FROM optionTest`).WillReturnRows(rows)
+
+	return db.QueryRow(`SELECT val FROM optionTest`).Scan(dst)
+}
+
+func TestSqlScanConversions(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		name string
+		src  any
+		dst  sql.Scanner
+	}{
+		{"int from int64", int64(42), &optional.Int{}},
+		{"int from float64", float64(42), &optional.Int{}},
+		{"int from string", "42", &optional.Int{}},
+		{"int from []byte", []byte("42"), &optional.Int{}},
+		{"int from bool", true, &optional.Int{}},
+		{"negative int8", int64(-8), &optional.Int8{}},
+		{"uint from int64", int64(42), &optional.Uint{}},
+		{"uint64 from string", "18446744073709551615", &optional.Uint64{}},
+		{"float32 from float64", float64(1.5), &optional.Float32{}},
+		{"float32 from int64", int64(2), &optional.Float32{}},
+		{"float64 from []byte", []byte("1.5"), &optional.Float64{}},
+		{"str from int64", int64(42), &optional.Str{}},
+		{"str from time", now, &optional.Str{}},
+		{"bool from int64", int64(1), &optional.Bool{}},
+		{"bool from []byte", []byte("false"), &optional.Bool{}},
+		{"byte from int64", int64(255), &optional.Byte{}},
+		{"duration from string", "1m", &optional.Duration{}},
+		{"duration from float64", float64(1000), &optional.Duration{}},
+		{"time from []byte", []byte(now.Format(time.RFC3339Nano)), &optional.Time{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := scanOne(t, c.src, c.dst)
+			assert.NilError(t, err)
+			assert.Assert(t, c.dst.(interface{ IsSome() bool }).IsSome())
+		})
+	}
+}
+
+func TestSqlScanValues(t *testing.T) {
+	var i optional.Int
+	assert.NilError(t, scanOne(t, float64(-7), &i))
+	assert.Assert(t, i.Match(-7))
+
+	var i8 optional.Int8
+	assert.NilError(t, scanOne(t, int64(-8), &i8))
+	assert.Assert(t, i8.Match(-8))
+
+	var b optional.Bool
+	assert.NilError(t, scanOne(t, int64(0), &b))
+	assert.Assert(t, b.Match(false))
+
+	var s optional.Str
+	assert.NilError(t, scanOne(t, float64(1.5), &s))
+	assert.Assert(t, s.Match("1.5"))
+
+	var d optional.Duration
+	assert.NilError(t, scanOne(t, "90s", &d))
+	assert.Assert(t, d.Match(90*time.Second))
+
+	// Floats past the range of int64 still fit in a uint64
+	var u64 optional.Uint64
+	assert.NilError(t, scanOne(t, float64(1<<63), &u64))
+	assert.Assert(t, u64.Match(1<<63))
+}
+
+func TestSqlScanRangeErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		src  any
+		dst  sql.Scanner
+	}{
+		{"int8 overflow", int64(300), &optional.Int8{}},
+		{"int16 from string overflow", "40000", &optional.Int16{}},
+		{"uint negative", int64(-1), &optional.Uint{}},
+		{"uint8 overflow", int64(256), &optional.Uint8{}},
+		{"uint from negative float", float64(-1), &optional.Uint{}},
+		{"uint64 float overflow", float64(1 << 64), &optional.Uint64{}},
+		{"uint32 float overflow", float64(1 << 32), &optional.Uint32{}},
+		{"int from fractional float", float64(1.5), &optional.Int{}},
+		{"float32 overflow", float64(1e300), &optional.Float32{}},
+		{"int from time", time.Now(), &optional.Int{}},
+		{"bool from 2", int64(2), &optional.Bool{}},
+		{"byte overflow", int64(256), &optional.Byte{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := scanOne(t, c.src, c.dst)
+			assert.Assert(t, err != nil)
+		})
+	}
+}
+
+func TestSqlValueLargeUint(t *testing.T) {
+	o := optional.SomeUint(^uint(0))
+	val, err := o.Value()
+	assert.NilError(t, err)
+	if strconv.IntSize == 64 {
+		assert.Equal(t, "18446744073709551615", val)
+	}
+
+	var out optional.Uint
+	err = out.Scan(val)
+	assert.NilError(t, err)
+	assert.Assert(t, out.Match(^uint(0)))
+}
@@ -60,21 +60,25 @@ func (o *Str) UnmarshalText(text []byte) error {
 	return nil
 }
 
-// Scan implements database/sql.Scanner interface.
+// Cmp compares o with other the same as Compare, with None sorting first. It can be passed straight to slices.SortFunc
+// as Str.Cmp.
+func (o Str) Cmp(other Str) int {
+	return Compare(o.Option, other.Option, true)
+}
+
+// Scan implements database/sql.Scanner interface. Besides strings and []byte, numbers, bools, and time.Time values
+// are accepted and converted to their string representation.
 func (o *Str) Scan(src any) error {
 	if src == nil {
 		// NULL value row
 		o.Clear()
 		return nil
 	}
-	switch t := src.(type) {
-	case string:
-		_ = o.Replace(t)
-	case []byte:
-		_ = o.Replace(string(t))
-	default:
-		return fmt.Errorf("converting driver.Value type %T to %s", src, o.Type())
+	val, err := scanString(src)
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
 	}
+	_ = o.Replace(val)
 	return nil
 }
 
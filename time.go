@@ -4,6 +4,10 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,25 +16,64 @@ const (
 	DEFAULT_TIME_STRING_FORMAT string = time.DateTime
 )
 
+// Special values for Time.StringFormat and Time.DataFormat which write the time as a unix timestamp in the given unit
+// rather than formatting it with a layout. When DataFormat is one of these, MarshalJSON writes a json number and Value
+// stores an int64.
+const (
+	EPOCH_SECONDS_FORMAT      string = "epoch:s"
+	EPOCH_MILLISECONDS_FORMAT string = "epoch:ms"
+	EPOCH_MICROSECONDS_FORMAT string = "epoch:us"
+	EPOCH_NANOSECONDS_FORMAT  string = "epoch:ns"
+)
+
+var epochFormatUnits = map[string]time.Duration{
+	EPOCH_SECONDS_FORMAT:      time.Second,
+	EPOCH_MILLISECONDS_FORMAT: time.Millisecond,
+	EPOCH_MICROSECONDS_FORMAT: time.Microsecond,
+	EPOCH_NANOSECONDS_FORMAT:  time.Nanosecond,
+}
+
 var DefaultExtraTimeFormats []string = []string{time.RFC3339Nano, time.RFC3339, time.UnixDate, time.RubyDate, time.RFC822, time.RFC822Z}
 
+// DefaultEpochUnit is the unit used to interpret numeric timestamps for Times which have neither an EpochUnit nor an
+// epoch DataFormat.
+var DefaultEpochUnit time.Duration = time.Second
+
 func SetDefaultExtraTimeFormats(formats []string) {
 	DefaultExtraTimeFormats = formats
 }
 
+func SetDefaultEpochUnit(unit time.Duration) {
+	DefaultEpochUnit = unit
+}
+
+// DefaultTimeLocation is used by every Time which was not given its own location with WithLocation. When it is nil,
+// which is the default, layouts without a zone are parsed as UTC and times are formatted in whatever location they
+// hold.
+var DefaultTimeLocation *time.Location
+
+func SetDefaultTimeLocation(loc *time.Location) {
+	DefaultTimeLocation = loc
+}
+
 type Time struct {
 	Option[time.Time]
 	StringFormat string
 	DataFormat   string
-	formats      []string
+	// EpochUnit is the unit of numeric timestamps, e.g. time.Millisecond. Numbers are accepted from JSON, SQL, TOML, and
+	// text wherever a formatted time could not be parsed. If EpochUnit is zero, the unit of an epoch DataFormat is
+	// used, falling back to DefaultEpochUnit.
+	EpochUnit time.Duration
+	formats   []string
+	loc       *time.Location
 }
 
 func SomeTime(value time.Time, formats ...string) Time {
-	return Time{Some(value), DEFAULT_TIME_STRING_FORMAT, DEFAULT_TIME_FORMAT, formats}
+	return Time{Some(value), DEFAULT_TIME_STRING_FORMAT, DEFAULT_TIME_FORMAT, 0, formats, nil}
 }
 
 func NoTime(formats ...string) Time {
-	return Time{None[time.Time](), DEFAULT_TIME_STRING_FORMAT, DEFAULT_TIME_FORMAT, formats}
+	return Time{None[time.Time](), DEFAULT_TIME_STRING_FORMAT, DEFAULT_TIME_FORMAT, 0, formats, nil}
 }
 
 func (o Time) WithFormats(formats ...string) Time {
@@ -41,6 +84,158 @@ func (o Time) WithFormats(formats ...string) Time {
 	return o
 }
 
+// WithEpochUnit returns a copy of the Time which reads numeric timestamps in unit.
+func (o Time) WithEpochUnit(unit time.Duration) Time {
+	o.EpochUnit = unit
+	return o
+}
+
+// WithLocation returns a copy of the Time which parses layouts without a zone in loc, using time.ParseInLocation, and
+// converts to loc before formatting in String and MarshalText. Passing nil reverts to DefaultTimeLocation.
+func (o Time) WithLocation(loc *time.Location) Time {
+	o.loc = loc
+	return o
+}
+
+// Location returns the location used for parsing and formatting, or nil if there is none.
+func (o Time) Location() *time.Location {
+	if o.loc != nil {
+		return o.loc
+	}
+	return DefaultTimeLocation
+}
+
+// inLocation converts t to the Time's location for formatting, if it has one.
+func (o Time) inLocation(t time.Time) time.Time {
+	if loc := o.Location(); loc != nil {
+		return t.In(loc)
+	}
+	return t
+}
+
+func (o Time) parse(layout, value string) (time.Time, error) {
+	if loc := o.Location(); loc != nil {
+		return time.ParseInLocation(layout, value, loc)
+	}
+	return time.Parse(layout, value)
+}
+
+func (o Time) epochUnit() time.Duration {
+	if o.EpochUnit > 0 {
+		return o.EpochUnit
+	}
+	if unit, ok := epochFormatUnits[o.DataFormat]; ok {
+		return unit
+	}
+	if DefaultEpochUnit > 0 {
+		return DefaultEpochUnit
+	}
+	return time.Second
+}
+
+// formatTime formats t with layout, which may also be one of the epoch formats.
+func formatTime(t time.Time, layout string) string {
+	unit, ok := epochFormatUnits[layout]
+	if !ok {
+		return t.Format(layout)
+	}
+	return strconv.FormatInt(toEpoch(t, unit), 10)
+}
+
+func toEpoch(t time.Time, unit time.Duration) int64 {
+	switch unit {
+	case time.Second:
+		return t.Unix()
+	case time.Millisecond:
+		return t.UnixMilli()
+	case time.Microsecond:
+		return t.UnixMicro()
+	default:
+		return t.UnixNano() / int64(unit)
+	}
+}
+
+// fromEpoch converts a timestamp counting unit since the unix epoch into a time.Time.
+func fromEpoch(n int64, unit time.Duration) (time.Time, error) {
+	return fromRatEpoch(new(big.Rat).SetInt64(n), unit)
+}
+
+// fromFloatEpoch is the same as fromEpoch, but allows fractional timestamps such as 1700000000.5 seconds.
+func fromFloatEpoch(f float64, unit time.Duration) (time.Time, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return time.Time{}, fmt.Errorf("timestamp %g is not a finite number", f)
+	}
+	return fromRatEpoch(new(big.Rat).SetFloat64(f), unit)
+}
+
+// maxEpochLength and maxEpochExponent bound the timestamps parseEpoch passes to big.Rat, which handles exponents
+// exactly, so that input such as 1e99999999 is rejected up front instead of using seconds of CPU and lots of memory.
+// No timestamp which fits in a time.Time needs more.
+const (
+	maxEpochLength   = 64
+	maxEpochExponent = 32
+)
+
+// parseEpoch parses text holding an integer or decimal timestamp. Decimals are parsed exactly rather than through a
+// float64, which can't represent nanoseconds for current dates.
+func parseEpoch(text string, unit time.Duration) (time.Time, error) {
+	if err := checkEpochText(text); err != nil {
+		return time.Time{}, err
+	}
+	r, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%q is not a numeric timestamp", text)
+	}
+	return fromRatEpoch(r, unit)
+}
+
+// checkEpochText only allows plain decimal numbers with an optional sign, fraction, and exponent, within the limits
+// above. big.Rat would also accept fractions like 1/3 and hex or binary exponents.
+func checkEpochText(text string) error {
+	if len(text) > maxEpochLength {
+		return fmt.Errorf("numeric timestamp is longer than %d characters", maxEpochLength)
+	}
+
+	mantissa, exp, hasExp := strings.Cut(strings.ToLower(text), "e")
+	if mantissa != "" && (mantissa[0] == '+' || mantissa[0] == '-') {
+		mantissa = mantissa[1:]
+	}
+	whole, frac, _ := strings.Cut(mantissa, ".")
+	if whole+frac == "" || !isDigits(whole) || !isDigits(frac) {
+		return fmt.Errorf("%q is not a numeric timestamp", text)
+	}
+	if hasExp {
+		n, err := strconv.Atoi(exp)
+		if err != nil {
+			return fmt.Errorf("%q is not a numeric timestamp", text)
+		}
+		if n > maxEpochExponent || n < -maxEpochExponent {
+			return fmt.Errorf("exponent of timestamp %q is out of range", text)
+		}
+	}
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func fromRatEpoch(r *big.Rat, unit time.Duration) (time.Time, error) {
+	ns := new(big.Rat).Mul(r, new(big.Rat).SetInt64(int64(unit)))
+	whole := new(big.Int).Div(ns.Num(), ns.Denom())
+
+	sec, nsec := new(big.Int).DivMod(whole, big.NewInt(int64(time.Second)), new(big.Int))
+	if !sec.IsInt64() {
+		return time.Time{}, fmt.Errorf("timestamp %s overflows with unit %s", r.FloatString(9), unit)
+	}
+	return time.Unix(sec.Int64(), nsec.Int64()), nil
+}
+
 func (o *Time) defaultFormatsIfEmpty() {
 	if o.StringFormat == "" {
 		o.StringFormat = DEFAULT_TIME_STRING_FORMAT
@@ -57,6 +252,12 @@ func (o Time) Formats() []string {
 	return o.formats
 }
 
+// Cmp compares o with other using time.Time.Compare, with None sorting first. It can be passed straight to
+// slices.SortFunc as Time.Cmp.
+func (o Time) Cmp(other Time) int {
+	return CompareFunc(o.Option, other.Option, true, time.Time.Compare)
+}
+
 func (o Time) Type() string {
 	return "Time"
 }
@@ -74,7 +275,7 @@ func (o Time) String() string {
 			return "Error[Time]"
 		}
 		o.defaultFormatsIfEmpty()
-		return tmp.Format(o.StringFormat)
+		return formatTime(o.inLocation(tmp), o.StringFormat)
 	}
 }
 
@@ -88,58 +289,123 @@ func (o Time) MarshalText() (text []byte, err error) {
 			err = optionalError("Attempted to Get Option with None value")
 		}
 		o.defaultFormatsIfEmpty()
-		return []byte(tmp.Format(o.DataFormat)), err
+		return []byte(formatTime(o.inLocation(tmp), o.DataFormat)), err
+	}
+}
+
+// TimeLayoutError records why a value could not be parsed with a single layout.
+type TimeLayoutError struct {
+	Layout string
+	Err    error
+}
+
+// TimeParseError is returned when a value can't be parsed by any of a Time's layouts. It lists every layout which was
+// attempted along with the reason each one failed, including the final attempt to read the value as a unix timestamp.
+// Set, UnmarshalText, UnmarshalJSON, and Scan all return it, wrapped where needed so that errors.As can find it.
+type TimeParseError struct {
+	Value    string
+	Attempts []TimeLayoutError
+}
+
+func (e *TimeParseError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "cannot parse %q as a time, tried %d layouts:", e.Value, len(e.Attempts))
+	for _, a := range e.Attempts {
+		fmt.Fprintf(&b, "\n\t%q: %v", a.Layout, a.Err)
+	}
+	return b.String()
+}
+
+// Unwrap returns the error from every attempted layout, so errors.As can find a *time.ParseError for example.
+func (e *TimeParseError) Unwrap() []error {
+	errs := make([]error, len(e.Attempts))
+	for i, a := range e.Attempts {
+		errs[i] = a.Err
+	}
+	return errs
+}
+
+// epochFormatName returns the epoch format matching unit, for use in error messages.
+func epochFormatName(unit time.Duration) string {
+	for format, u := range epochFormatUnits {
+		if u == unit {
+			return format
+		}
 	}
+	return "epoch:" + unit.String()
 }
 
 func (o *Time) UnmarshalText(text []byte) error {
 	tmp := string(text)
 	if tmp == "None" || tmp == "none" || tmp == "null" || tmp == "nil" {
 		o.Clear()
-	} else {
-		l := len(o.formats)
-		if l == 0 {
-			// No formats?
-			o.defaultFormatsIfEmpty()
-			l = len(o.formats)
-		}
-		for i, f := range o.formats {
-			t, err := time.Parse(f, tmp)
-			if err == nil {
-				o.Replace(t)
-				break
-			}
-			if i == l-1 {
-				// Tried all formats without success. This only gives the error for the last format, but hopefully that
-				// should be enough for now. Maybe give a more informative error message in the future?
-				return err
-			}
+		return nil
+	}
+
+	if len(o.formats) == 0 {
+		// No formats?
+		o.defaultFormatsIfEmpty()
+	}
+
+	parseErr := &TimeParseError{Value: tmp}
+	for _, f := range o.formats {
+		t, err := o.parse(f, tmp)
+		if err == nil {
+			o.Replace(t)
+			return nil
 		}
+		parseErr.Attempts = append(parseErr.Attempts, TimeLayoutError{Layout: f, Err: err})
 	}
 
-	return nil
+	// Numeric timestamps are only tried once every layout has failed, so layouts made entirely of digits still take
+	// priority.
+	unit := o.epochUnit()
+	t, err := parseEpoch(tmp, unit)
+	if err == nil {
+		o.Replace(t)
+		return nil
+	}
+	parseErr.Attempts = append(parseErr.Attempts, TimeLayoutError{Layout: epochFormatName(unit), Err: err})
+	return parseErr
 }
 
 // Marshaler interface
 
+// MarshalJSON writes the time as a json string formatted with DataFormat, or as a json number if DataFormat is one of
+// the epoch formats.
 func (o Time) MarshalJSON() ([]byte, error) {
 	if o.IsNone() {
 		return json.Marshal(nil)
 	} else {
 		tmp, err := o.MarshalText()
+		if _, ok := epochFormatUnits[o.DataFormat]; ok {
+			return tmp, err
+		}
 		ret := append([]byte(`"`), tmp...)
 		ret = append(ret, '"')
 		return ret, err
 	}
 }
 
-// UnmarshalJSON implements encoding/json.Unmarshaller interface
+// UnmarshalJSON implements encoding/json.Unmarshaller interface. Json numbers are read as unix timestamps in the
+// Time's epoch unit.
 func (o *Time) UnmarshalJSON(data []byte) error {
 	if string(data) == "null" {
 		o.Clear()
 		return nil
 	}
 
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err == nil && len(data) > 0 && data[0] != '"' {
+		unit := o.epochUnit()
+		t, err := parseEpoch(n.String(), unit)
+		if err != nil {
+			return &TimeParseError{Value: n.String(), Attempts: []TimeLayoutError{{Layout: epochFormatName(unit), Err: err}}}
+		}
+		_ = o.Replace(t)
+		return nil
+	}
+
 	// TODO: think if a better way to do this.
 	var s string
 	err := json.Unmarshal(data, &s)
@@ -150,26 +416,79 @@ func (o *Time) UnmarshalJSON(data []byte) error {
 	return o.UnmarshalText([]byte(s))
 }
 
-// Scan implements database/sql.Scanner interface.
+// MarshalTOML implements the toml.Marshaler interface. The time is converted to the Time's location and written as a
+// native TOML datetime when DataFormat is RFC3339, as a TOML integer when DataFormat is one of the epoch formats, and
+// otherwise as a string formatted with DataFormat. None values are written as the string "None".
+func (o Time) MarshalTOML() ([]byte, error) {
+	if o.IsNone() {
+		return []byte(`"None"`), nil
+	}
+
+	o.defaultFormatsIfEmpty()
+	if o.DataFormat == time.RFC3339 || o.DataFormat == time.RFC3339Nano {
+		return []byte(o.inLocation(o.MustGet()).Format(o.DataFormat)), nil
+	}
+	tmp, err := o.MarshalText()
+	if _, ok := epochFormatUnits[o.DataFormat]; ok {
+		return tmp, err
+	}
+	return []byte(tomlQuote(string(tmp))), err
+}
+
+// UnmarshalTOML implements the toml.Unmarshaler interface. Native TOML datetimes are used directly, while strings are
+// parsed using the same formats as UnmarshalText and numbers are read as unix timestamps.
+func (o *Time) UnmarshalTOML(data any) error {
+	switch t := data.(type) {
+	case time.Time:
+		_ = o.Replace(t)
+	case string:
+		return o.UnmarshalText([]byte(t))
+	case int64:
+		return o.replaceEpoch(fromEpoch(t, o.epochUnit()))
+	case float64:
+		return o.replaceEpoch(fromFloatEpoch(t, o.epochUnit()))
+	default:
+		return fmt.Errorf("converting TOML type %T to %s", data, o.Type())
+	}
+	return nil
+}
+
+func (o *Time) replaceEpoch(t time.Time, err error) error {
+	if err != nil {
+		return err
+	}
+	_ = o.Replace(t)
+	return nil
+}
+
+// Scan implements database/sql.Scanner interface. int64 and float64 values are read as unix timestamps in the Time's
+// epoch unit.
 func (o *Time) Scan(src any) error {
 	if src == nil {
 		// NULL value row
 		o.Clear()
 		return nil
 	}
-	// TODO: support an int64 as a unix timestamp?
 	switch t := src.(type) {
 	case time.Time:
 		_ = o.Replace(t)
+	case int64:
+		if err := o.replaceEpoch(fromEpoch(t, o.epochUnit())); err != nil {
+			return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
+		}
+	case float64:
+		if err := o.replaceEpoch(fromFloatEpoch(t, o.epochUnit())); err != nil {
+			return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
+		}
 	case string:
 		err := o.UnmarshalText([]byte(t))
 		if err != nil {
-			return err
+			return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
 		}
 	case []byte:
 		err := o.UnmarshalText(t)
 		if err != nil {
-			return err
+			return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
 		}
 	default:
 		return fmt.Errorf("converting driver.Value type %T to %s", src, o.Type())
@@ -177,10 +496,14 @@ func (o *Time) Scan(src any) error {
 	return nil
 }
 
-// Value implements the database/sql/driver.Valuer interface
+// Value implements the database/sql/driver.Valuer interface. If DataFormat is one of the epoch formats the time is
+// stored as an int64 timestamp, otherwise as a time.Time.
 func (o Time) Value() (driver.Value, error) {
 	val, ok := o.Get()
 	if ok {
+		if unit, isEpoch := epochFormatUnits[o.DataFormat]; isEpoch {
+			return toEpoch(val, unit), nil
+		}
 		return val, nil
 	}
 	return nil, nil
@@ -188,6 +511,10 @@ func (o Time) Value() (driver.Value, error) {
 
 // Duration is the optional version of time.Duration. Under the hood, time.Duration is an int64
 // but we still use an underlying Option[time.Duration] type to get access to all the associated methods.
+//
+// Besides the units time.ParseDuration understands, durations may be written with d (24 hours) and w (7 days) units,
+// e.g. 1w2d, as ISO 8601 durations such as P1DT2H, or as Postgres intervals such as "1 day 02:03:04". To write them in
+// one of those formats, see WithFormat.
 type Duration struct {
 	Option[time.Duration]
 }
@@ -200,6 +527,22 @@ func NoDuration(formats ...string) Duration {
 	return Duration{None[time.Duration]()}
 }
 
+// WithFormat returns a FormattedDuration holding the same value, which uses format for both String and MarshalText.
+func (o Duration) WithFormat(format string) FormattedDuration {
+	return FormattedDuration{Duration: o}.WithFormat(format)
+}
+
+// WithPrecision returns a FormattedDuration holding the same value, which rounds to a multiple of precision in String.
+func (o Duration) WithPrecision(precision time.Duration) FormattedDuration {
+	return FormattedDuration{Duration: o}.WithPrecision(precision)
+}
+
+// Cmp compares o with other the same as Compare, with None sorting first. It can be passed straight to slices.SortFunc
+// as Duration.Cmp.
+func (o Duration) Cmp(other Duration) int {
+	return Compare(o.Option, other.Option, true)
+}
+
 func (o Duration) Type() string {
 	return "Duration"
 }
@@ -216,7 +559,7 @@ func (o Duration) String() string {
 		if !ok {
 			return "Error[Duration]"
 		}
-		// TODO: make the number of significant figures printed configurable?
+		// Use WithFormat and WithPrecision to choose how many significant figures are printed.
 		return tmp.String()
 	}
 }
@@ -239,7 +582,7 @@ func (o *Duration) UnmarshalText(text []byte) error {
 	if tmp == "None" || tmp == "none" || tmp == "null" || tmp == "nil" {
 		o.Clear()
 	} else {
-		d, err := time.ParseDuration(tmp)
+		d, err := parseDuration(tmp)
 		if err != nil {
 			return err
 		}
@@ -278,20 +621,50 @@ func (o *Duration) UnmarshalJSON(data []byte) error {
 	return o.UnmarshalText([]byte(s))
 }
 
-// Scan implements database/sql.Scanner interface.
+// MarshalTOML implements the toml.Marshaler interface. Durations are written as strings such as "1m30s", the same as
+// MarshalText, since TOML has no native duration type.
+func (o Duration) MarshalTOML() ([]byte, error) {
+	tmp, err := o.MarshalText()
+	return []byte(tomlQuote(string(tmp))), err
+}
+
+// UnmarshalTOML implements the toml.Unmarshaler interface. Strings are parsed the same as UnmarshalText and integers
+// are treated as a number of nanoseconds.
+func (o *Duration) UnmarshalTOML(data any) error {
+	switch t := data.(type) {
+	case int64:
+		_ = o.Replace(time.Duration(t))
+	case string:
+		return o.UnmarshalText([]byte(t))
+	default:
+		return fmt.Errorf("converting TOML type %T to %s", data, o.Type())
+	}
+	return nil
+}
+
+// Scan implements database/sql.Scanner interface. Strings and []byte are parsed the same as UnmarshalText, which
+// includes Postgres intervals, while numbers are treated as a count of nanoseconds.
 func (o *Duration) Scan(src any) error {
 	if src == nil {
 		// NULL value row
 		o.Clear()
 		return nil
 	}
-	switch src.(type) {
-	case time.Duration:
-		_ = o.Replace(src.(time.Duration))
-	case int64:
-		_ = o.Replace(time.Duration(src.(int64)))
+	switch t := src.(type) {
+	case string:
+		if err := o.UnmarshalText([]byte(t)); err != nil {
+			return err
+		}
+	case []byte:
+		if err := o.UnmarshalText(t); err != nil {
+			return err
+		}
 	default:
-		return fmt.Errorf("converting driver.Value type %T to %s", src, o.Type())
+		val, err := scanNumber[time.Duration](src)
+		if err != nil {
+			return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
+		}
+		_ = o.Replace(val)
 	}
 	return nil
 }
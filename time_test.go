@@ -2,13 +2,19 @@ package optional_test
 
 import (
 	"encoding/json"
+	"errors"
+	"flag"
+	"math"
 	"reflect"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/brnsampson/optional"
+	"go-simpler.org/env"
 	"gotest.tools/v3/assert"
 )
 
@@ -182,6 +188,183 @@ func TestTimeSql(t *testing.T) {
 	}
 }
 
+func TestTimeEpochUnmarshalJson(t *testing.T) {
+	expected := time.UnixMilli(1700000000123)
+
+	o := optional.NoTime().WithEpochUnit(time.Millisecond)
+	assert.NilError(t, json.Unmarshal([]byte("1700000000123"), &o))
+	assert.Assert(t, o.MustGet().Equal(expected))
+
+	// Seconds are the default unit
+	o = optional.NoTime()
+	assert.NilError(t, json.Unmarshal([]byte("1700000000.123"), &o))
+	assert.Assert(t, o.MustGet().Equal(expected))
+
+	o = optional.NoTime()
+	assert.NilError(t, json.Unmarshal([]byte("-1"), &o))
+	assert.Assert(t, o.MustGet().Equal(time.Unix(-1, 0)))
+
+	// Quoted numbers go through the text path, which also accepts timestamps
+	o = optional.NoTime().WithEpochUnit(time.Microsecond)
+	assert.NilError(t, json.Unmarshal([]byte(`"1700000000123000"`), &o))
+	assert.Assert(t, o.MustGet().Equal(expected))
+
+	assert.Assert(t, json.Unmarshal([]byte("1e300"), &o) != nil)
+
+	// Exponents are allowed, but huge ones are rejected before any work is done with them
+	o = optional.NoTime()
+	assert.NilError(t, json.Unmarshal([]byte("1.7e9"), &o))
+	assert.Assert(t, o.MustGet().Equal(time.Unix(1700000000, 0)))
+	for _, bad := range []string{"1e99999999", "1e-99999999", "1" + strings.Repeat("0", 100)} {
+		start := time.Now()
+		assert.Assert(t, json.Unmarshal([]byte(bad), &o) != nil)
+		assert.Assert(t, time.Since(start) < time.Second)
+	}
+	assert.Assert(t, o.Set("0x1p4") != nil)
+	assert.Assert(t, o.Set("1/3") != nil)
+}
+
+func TestTimeEpochUnmarshalText(t *testing.T) {
+	units := map[time.Duration]string{
+		time.Second:      "1700000000",
+		time.Millisecond: "1700000000000",
+		time.Microsecond: "1700000000000000",
+		time.Nanosecond:  "1700000000000000000",
+	}
+	for unit, text := range units {
+		o := optional.NoTime().WithEpochUnit(unit)
+		assert.NilError(t, o.Set(text))
+		assert.Assert(t, o.MustGet().Equal(time.Unix(1700000000, 0)), unit)
+	}
+
+	// Layouts take priority over timestamps
+	o := optional.NoTime("20060102")
+	assert.NilError(t, o.Set("20231114"))
+	assert.Assert(t, o.MustGet().Equal(time.Date(2023, 11, 14, 0, 0, 0, 0, time.UTC)))
+
+	o = optional.NoTime()
+	assert.Assert(t, o.Set("not a time") != nil)
+}
+
+func TestTimeEpochDataFormat(t *testing.T) {
+	o := optional.SomeTime(time.UnixMilli(1700000000123))
+	o.DataFormat = optional.EPOCH_MILLISECONDS_FORMAT
+
+	text, err := o.MarshalText()
+	assert.NilError(t, err)
+	assert.Equal(t, "1700000000123", string(text))
+
+	data, err := json.Marshal(o)
+	assert.NilError(t, err)
+	assert.Equal(t, "1700000000123", string(data))
+
+	val, err := o.Value()
+	assert.NilError(t, err)
+	assert.Equal(t, int64(1700000000123), val)
+
+	// The epoch DataFormat also sets the unit used when reading numbers back
+	out := optional.NoTime()
+	out.DataFormat = optional.EPOCH_MILLISECONDS_FORMAT
+	assert.NilError(t, json.Unmarshal(data, &out))
+	assert.Assert(t, out.MustGet().Equal(o.MustGet()))
+
+	o.DataFormat = optional.EPOCH_SECONDS_FORMAT
+	text, err = o.MarshalText()
+	assert.NilError(t, err)
+	assert.Equal(t, "1700000000", string(text))
+
+	o.StringFormat = optional.EPOCH_NANOSECONDS_FORMAT
+	assert.Equal(t, "1700000000123000000", o.String())
+}
+
+func TestTimeEpochScan(t *testing.T) {
+	o := optional.NoTime().WithEpochUnit(time.Millisecond)
+	assert.NilError(t, o.Scan(int64(1700000000123)))
+	assert.Assert(t, o.MustGet().Equal(time.UnixMilli(1700000000123)))
+
+	assert.NilError(t, o.Scan(float64(1700000000123.5)))
+	assert.Assert(t, o.MustGet().Equal(time.UnixMicro(1700000000123500)))
+
+	assert.NilError(t, o.Scan([]byte("1700000000124")))
+	assert.Assert(t, o.MustGet().Equal(time.UnixMilli(1700000000124)))
+
+	big := optional.NoTime().WithEpochUnit(time.Hour)
+	assert.Assert(t, big.Scan(int64(math.MaxInt64)) != nil)
+}
+
+func TestTimeParseError(t *testing.T) {
+	o := optional.NoTime(time.RFC3339, time.DateOnly)
+	err := o.Set("yesterday")
+
+	var parseErr *optional.TimeParseError
+	assert.Assert(t, errors.As(err, &parseErr))
+	assert.Equal(t, "yesterday", parseErr.Value)
+	assert.Equal(t, 3, len(parseErr.Attempts))
+	assert.Equal(t, time.RFC3339, parseErr.Attempts[0].Layout)
+	assert.Equal(t, time.DateOnly, parseErr.Attempts[1].Layout)
+	assert.Equal(t, optional.EPOCH_SECONDS_FORMAT, parseErr.Attempts[2].Layout)
+	assert.Assert(t, o.IsNone())
+
+	// Every layout is named in the message
+	assert.ErrorContains(t, err, time.RFC3339)
+	assert.ErrorContains(t, err, time.DateOnly)
+
+	// The underlying errors from the time package are still reachable
+	var timeErr *time.ParseError
+	assert.Assert(t, errors.As(err, &timeErr))
+
+	err = json.Unmarshal([]byte(`{"t":"yesterday"}`), &struct{ T optional.Time }{T: o})
+	assert.Assert(t, errors.As(err, &parseErr))
+
+	err = json.Unmarshal([]byte("1e300"), &o)
+	assert.Assert(t, errors.As(err, &parseErr))
+	assert.Equal(t, 1, len(parseErr.Attempts))
+
+	err = o.Scan([]byte("yesterday"))
+	assert.Assert(t, errors.As(err, &parseErr))
+	assert.ErrorContains(t, err, "converting driver.Value")
+}
+
+func TestTimeLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.NilError(t, err)
+
+	// Layouts without a zone are parsed in the Time's location rather than UTC
+	o := optional.NoTime(time.DateTime).WithLocation(tokyo)
+	assert.Equal(t, tokyo, o.Location())
+	assert.NilError(t, o.Set("2024-01-02 09:00:00"))
+	assert.Assert(t, o.MustGet().Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)))
+
+	// Values are converted to the location before formatting
+	utc := optional.SomeTime(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)).WithLocation(tokyo)
+	assert.Equal(t, "2024-01-02 09:00:00", utc.String())
+	text, err := utc.MarshalText()
+	assert.NilError(t, err)
+	assert.Equal(t, "2024-01-02T09:00:00+09:00", string(text))
+
+	// Layouts with a zone still use it
+	o = o.WithFormats(time.RFC3339)
+	assert.NilError(t, o.Set("2024-01-02T00:00:00Z"))
+	assert.Assert(t, o.MustGet().Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestTimeDefaultLocation(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	assert.NilError(t, err)
+
+	optional.SetDefaultTimeLocation(paris)
+	defer optional.SetDefaultTimeLocation(nil)
+
+	o := optional.NoTime(time.DateTime)
+	assert.Equal(t, paris, o.Location())
+	assert.NilError(t, o.Set("2024-01-02 01:00:00"))
+	assert.Assert(t, o.MustGet().Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)))
+
+	// A per value location overrides the default
+	o = o.WithLocation(time.UTC)
+	assert.Equal(t, "2024-01-02 00:00:00", o.String())
+}
+
 func TestDurationType(t *testing.T) {
 	d, err := time.ParseDuration("300ms")
 	assert.NilError(t, err)
@@ -336,3 +519,157 @@ func TestDurationSql(t *testing.T) {
 		assert.Assert(t, out2.IsNone(), "None case failed")
 	}
 }
+
+func TestDurationUnmarshalExtended(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected time.Duration
+	}{
+		{"7d", 7 * 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"1w2d3h4m5s", (9*24+3)*time.Hour + 4*time.Minute + 5*time.Second},
+		{"1.5d", 36 * time.Hour},
+		{"-1d12h", -36 * time.Hour},
+		{"P1DT2H", 26 * time.Hour},
+		{"P2W", 14 * 24 * time.Hour},
+		{"PT1M30.5S", 90*time.Second + 500*time.Millisecond},
+		{"PT0,5S", 500 * time.Millisecond},
+		{"-PT30M", -30 * time.Minute},
+		{"3 days 04:05:06", (3*24+4)*time.Hour + 5*time.Minute + 6*time.Second},
+		{"-1 days +02:00:00", -22 * time.Hour},
+		{"00:00:01.5", 1500 * time.Millisecond},
+		{"-01:30:00", -90 * time.Minute},
+		{"1 day", 24 * time.Hour},
+		{"@ 2 days 3 hours ago", -51 * time.Hour},
+	}
+
+	for _, c := range cases {
+		var o optional.Duration
+		assert.NilError(t, o.UnmarshalText([]byte(c.text)), c.text)
+		assert.Equal(t, c.expected, o.MustGet(), c.text)
+	}
+
+	invalid := []string{"P1Y", "P1M", "1 mon", "2 years 1 day", "1x", "P", "PT", "P1DT", "-P2WT", "1d2", "P1H", "10:", "1 parsec"}
+	for _, text := range invalid {
+		var o optional.Duration
+		assert.Assert(t, o.UnmarshalText([]byte(text)) != nil, text)
+	}
+
+	var o optional.Duration
+	assert.ErrorContains(t, o.UnmarshalText([]byte("106752w")), "overflow")
+}
+
+func TestDurationFormats(t *testing.T) {
+	d := 50*time.Hour + 30*time.Minute + 1500*time.Millisecond
+
+	cases := []struct {
+		format   string
+		expected string
+	}{
+		{"", "50h30m1.5s"},
+		{optional.DURATION_GO_FORMAT, "50h30m1.5s"},
+		{optional.DURATION_DAYS_FORMAT, "2d2h30m1.5s"},
+		{optional.DURATION_ISO8601_FORMAT, "P2DT2H30M1.5S"},
+	}
+	for _, c := range cases {
+		o := optional.SomeDuration(d).WithFormat(c.format)
+		assert.Equal(t, c.expected, o.String(), c.format)
+
+		text, err := o.MarshalText()
+		assert.NilError(t, err)
+		assert.Equal(t, c.expected, string(text), c.format)
+
+		// Every format can be read back
+		var back optional.Duration
+		assert.NilError(t, back.UnmarshalText(text))
+		assert.Equal(t, d, back.MustGet(), c.format)
+	}
+
+	iso := optional.SomeDuration(0).WithFormat(optional.DURATION_ISO8601_FORMAT)
+	assert.Equal(t, "PT0S", iso.String())
+	iso = optional.SomeDuration(-48 * time.Hour).WithFormat(optional.DURATION_ISO8601_FORMAT)
+	assert.Equal(t, "-P2D", iso.String())
+
+	days := optional.SomeDuration(-24 * time.Hour).WithFormat(optional.DURATION_DAYS_FORMAT)
+	assert.Equal(t, "-1d", days.String())
+	days = optional.SomeDuration(90 * time.Minute).WithFormat(optional.DURATION_DAYS_FORMAT)
+	assert.Equal(t, "1h30m0s", days.String())
+
+	// Formats are kept when unmarshaling into an existing value
+	o := optional.NoDuration().WithFormat(optional.DURATION_ISO8601_FORMAT)
+	assert.NilError(t, json.Unmarshal([]byte(`"36h"`), &o))
+	res, err := json.Marshal(o)
+	assert.NilError(t, err)
+	assert.Equal(t, `"P1DT12H"`, string(res))
+}
+
+func TestDurationPrecision(t *testing.T) {
+	d := 1234567891 * time.Nanosecond
+	o := optional.SomeDuration(d).WithPrecision(time.Millisecond)
+	assert.Equal(t, "1.235s", o.String())
+
+	// MarshalText never rounds
+	text, err := o.MarshalText()
+	assert.NilError(t, err)
+	assert.Equal(t, d.String(), string(text))
+
+	o = optional.SomeDuration(90*time.Minute + 20*time.Second).WithPrecision(time.Minute)
+	o.StringFormat = optional.DURATION_ISO8601_FORMAT
+	assert.Equal(t, "PT1H30M", o.String())
+}
+
+func TestFormattedDurationConfigField(t *testing.T) {
+	type config struct {
+		Timeout optional.FormattedDuration `flag:"timeout" env:"TIMEOUT" json:"timeout" toml:"timeout"`
+	}
+	defaults := func() config {
+		return config{Timeout: optional.NoDuration().WithFormat(optional.DURATION_ISO8601_FORMAT)}
+	}
+
+	conf := defaults()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	assert.NilError(t, optional.BindFlags(fs, &conf))
+	assert.NilError(t, fs.Parse([]string{"-timeout", "90m"}))
+	data, err := json.Marshal(conf)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"timeout":"PT1H30M"}`, string(data))
+
+	conf = defaults()
+	assert.NilError(t, optional.LoadEnv(&conf, optional.WithEnvSource(env.Map{"TIMEOUT": "2d"})))
+	assert.Equal(t, "P2D", conf.Timeout.String())
+
+	conf = defaults()
+	_, err = toml.Decode(`timeout = "36h"`, &conf)
+	assert.NilError(t, err)
+	assert.Equal(t, "P1DT12H", conf.Timeout.String())
+
+	conf = defaults()
+	assert.NilError(t, json.Unmarshal([]byte(`{"timeout":"1w"}`), &conf))
+	assert.Equal(t, "P7D", conf.Timeout.String())
+}
+
+func TestDurationUnkeyedLiteral(t *testing.T) {
+	// Duration is still a single Option, so unkeyed literals keep working
+	o := optional.Duration{optional.Some(time.Second)}
+	assert.Equal(t, "1s", o.String())
+
+	// and formatting a value doesn't change what it holds
+	f := o.WithFormat(optional.DURATION_ISO8601_FORMAT)
+	assert.Equal(t, "PT1S", f.String())
+	assert.Equal(t, o, f.Duration)
+	assert.Equal(t, "None[Duration]", optional.NoDuration().WithFormat(optional.DURATION_ISO8601_FORMAT).String())
+}
+
+func TestDurationScanInterval(t *testing.T) {
+	var o optional.Duration
+	assert.NilError(t, o.Scan("1 day 02:00:00"))
+	assert.Equal(t, 26*time.Hour, o.MustGet())
+
+	assert.NilError(t, o.Scan([]byte("P1W")))
+	assert.Equal(t, 7*24*time.Hour, o.MustGet())
+
+	assert.NilError(t, o.Scan(int64(time.Second)))
+	assert.Equal(t, time.Second, o.MustGet())
+
+	assert.Assert(t, o.Scan("1 year") != nil)
+}
@@ -0,0 +1,308 @@
+package optional
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	tomlMarshalerType   = reflect.TypeOf((*toml.Marshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func isNoneString(s string) bool {
+	return s == "None" || s == "none" || s == "null" || s == "nil"
+}
+
+// EncodeTOML writes v to w as a TOML document the same way as toml.NewEncoder(w).Encode(v), except that every optional
+// field which is None is left out of the document instead of being written as the string "None". Fields of nested
+// structs and values of maps are pruned the same way. When the document is decoded again, the missing keys leave the
+// corresponding fields as None. Unlike the omitempty tag, this also leaves out None values of types which hold settings
+// such as Time, where the formats stop the toml encoder from seeing the value as empty.
+func EncodeTOML(w io.Writer, v any) error {
+	pruned := pruneNone(reflect.ValueOf(v))
+	if !pruned.IsValid() {
+		return fmt.Errorf("cannot encode %T as a TOML document", v)
+	}
+	return toml.NewEncoder(w).Encode(pruned.Interface())
+}
+
+// pruneNone returns a copy of v with every None optional removed. Structs are rebuilt as new anonymous struct types
+// which only contain the fields to keep, so that the field order and toml tags of the original are preserved.
+func pruneNone(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+
+	if isTOMLLeaf(v) {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		var fields []reflect.StructField
+		var values []reflect.Value
+		pruneStructFields(v, &fields, &values)
+
+		pruned := reflect.New(reflect.StructOf(fields)).Elem()
+		for i, val := range values {
+			pruned.Field(i).Set(val)
+		}
+		return pruned
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return v
+		}
+
+		pruned := reflect.ValueOf(map[string]any{})
+		iter := v.MapRange()
+		for iter.Next() {
+			val := pruneNone(iter.Value())
+			if val.IsValid() {
+				pruned.SetMapIndex(reflect.ValueOf(iter.Key().String()), val)
+			}
+		}
+		return pruned
+	default:
+		return v
+	}
+}
+
+func pruneStructFields(v reflect.Value, fields *[]reflect.StructField, values *[]reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		tag := sf.Tag.Get("toml")
+		if !sf.IsExported() || tag == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+		if _, ok := fv.Interface().(someable); ok && !isSomeField(fv) {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if sf.Anonymous && name == "" && fv.Kind() == reflect.Struct && !isTOMLLeaf(fv) {
+			// The toml encoder flattens embedded structs into their parent, so we do the same.
+			pruneStructFields(fv, fields, values)
+			continue
+		}
+
+		pruned := pruneNone(fv)
+		if !pruned.IsValid() {
+			continue
+		}
+
+		*fields = append(*fields, reflect.StructField{Name: sf.Name, Type: pruned.Type(), Tag: sf.Tag})
+		*values = append(*values, pruned)
+	}
+}
+
+// isTOMLLeaf reports if the toml encoder will write v as a single value rather than as a table.
+func isTOMLLeaf(v reflect.Value) bool {
+	t := v.Type()
+	return t == timeType || t.Implements(tomlMarshalerType) || t.Implements(textMarshalerType)
+}
+
+// marshalTOMLValue writes val as the native TOML type corresponding to its kind.
+func marshalTOMLValue(val any) ([]byte, error) {
+	switch t := val.(type) {
+	case time.Time:
+		return []byte(t.Format(time.RFC3339Nano)), nil
+	case toml.Marshaler:
+		return t.MarshalTOML()
+	case encoding.TextMarshaler:
+		text, err := t.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return []byte(tomlQuote(string(text))), nil
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return []byte(strconv.FormatInt(rv.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return []byte(strconv.FormatUint(rv.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		return []byte(tomlFloat(rv.Float(), rv.Type().Bits())), nil
+	case reflect.Bool:
+		return []byte(strconv.FormatBool(rv.Bool())), nil
+	case reflect.String:
+		return []byte(tomlQuote(rv.String())), nil
+	default:
+		return nil, fmt.Errorf("cannot marshal type %T into a TOML value", val)
+	}
+}
+
+// unmarshalNative converts a native value, such as those produced by the toml decoder or a database driver, into the
+// type pointed to by dst.
+func unmarshalNative(data any, dst any) error {
+	rv := reflect.ValueOf(dst).Elem()
+	dv := reflect.ValueOf(data)
+	if !dv.IsValid() {
+		return fmt.Errorf("cannot unmarshal value %v into %s", data, rv.Type())
+	}
+	if dv.Type() == rv.Type() {
+		rv.Set(dv)
+		return nil
+	}
+
+	// Types which know how to parse themselves take priority over converting by kind.
+	if s, ok := data.(string); ok && reflect.PointerTo(rv.Type()).Implements(textUnmarshalerType) {
+		return rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch t := data.(type) {
+		case int64:
+			i = t
+		case string:
+			tmp, err := strconv.ParseInt(t, 10, rv.Type().Bits())
+			if err != nil {
+				return err
+			}
+			i = tmp
+		default:
+			return fmt.Errorf("cannot unmarshal %T into %s", data, rv.Type())
+		}
+		if rv.OverflowInt(i) {
+			return fmt.Errorf("integer %d overflows %s", i, rv.Type())
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		switch t := data.(type) {
+		case uint64:
+			u = t
+		case int64:
+			if t < 0 {
+				return fmt.Errorf("integer %d overflows %s", t, rv.Type())
+			}
+			u = uint64(t)
+		case string:
+			tmp, err := strconv.ParseUint(t, 10, rv.Type().Bits())
+			if err != nil {
+				return err
+			}
+			u = tmp
+		default:
+			return fmt.Errorf("cannot unmarshal %T into %s", data, rv.Type())
+		}
+		if rv.OverflowUint(u) {
+			return fmt.Errorf("integer %d overflows %s", u, rv.Type())
+		}
+		rv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch t := data.(type) {
+		case float64:
+			f = t
+		case int64:
+			f = float64(t)
+		case string:
+			tmp, err := strconv.ParseFloat(t, rv.Type().Bits())
+			if err != nil {
+				return err
+			}
+			f = tmp
+		default:
+			return fmt.Errorf("cannot unmarshal %T into %s", data, rv.Type())
+		}
+		if !math.IsInf(f, 0) && rv.OverflowFloat(f) {
+			return fmt.Errorf("float %g overflows %s", f, rv.Type())
+		}
+		rv.SetFloat(f)
+	case reflect.Bool:
+		switch t := data.(type) {
+		case bool:
+			rv.SetBool(t)
+		case string:
+			b, err := strconv.ParseBool(t)
+			if err != nil {
+				return err
+			}
+			rv.SetBool(b)
+		default:
+			return fmt.Errorf("cannot unmarshal %T into %s", data, rv.Type())
+		}
+	case reflect.String:
+		s, ok := data.(string)
+		if !ok {
+			return fmt.Errorf("cannot unmarshal %T into %s", data, rv.Type())
+		}
+		rv.SetString(s)
+	default:
+		if !dv.Type().ConvertibleTo(rv.Type()) {
+			return fmt.Errorf("cannot unmarshal %T into %s", data, rv.Type())
+		}
+		rv.Set(dv.Convert(rv.Type()))
+	}
+	return nil
+}
+
+// tomlFloat formats f so that it is always read back as a TOML float, never an integer.
+func tomlFloat(f float64, bits int) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	}
+
+	s := strconv.FormatFloat(f, 'g', -1, bits)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return s
+}
+
+// tomlQuote writes s as a TOML basic string.
+func tomlQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,211 @@
+package optional_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/brnsampson/optional"
+	"gotest.tools/v3/assert"
+)
+
+type tomlTestServer struct {
+	Host optional.Str `toml:"host"`
+	Port optional.Int `toml:"port"`
+}
+
+type tomlTestConfig struct {
+	Count    optional.Int             `toml:"count"`
+	Small    optional.Int8            `toml:"small"`
+	Unsigned optional.Uint16          `toml:"unsigned"`
+	Ratio    optional.Float64         `toml:"ratio"`
+	Debug    optional.Bool            `toml:"debug"`
+	Started  optional.Time            `toml:"started"`
+	Timeout  optional.Duration        `toml:"timeout"`
+	Name     optional.Str             `toml:"name"`
+	Password optional.Secret          `toml:"password"`
+	Generic  optional.Option[int32]   `toml:"generic"`
+	Label    optional.Option[string]  `toml:"label"`
+	Missing  optional.Option[float32] `toml:"missing"`
+	Server   tomlTestServer           `toml:"server"`
+}
+
+const tomlTestDoc = `
+count = 42
+small = -3
+unsigned = 65535
+ratio = 0.25
+debug = true
+started = 2023-06-01T12:30:00Z
+timeout = "1m30s"
+name = "optional"
+password = "hunter2"
+generic = 7
+label = "None"
+
+[server]
+host = "localhost"
+`
+
+func TestTOMLDecode(t *testing.T) {
+	var conf tomlTestConfig
+	conf.Started = optional.NoTime().WithFormats(time.UnixDate)
+	_, err := toml.Decode(tomlTestDoc, &conf)
+	assert.NilError(t, err)
+
+	assert.Assert(t, conf.Count.Match(42))
+	assert.Assert(t, conf.Small.Match(-3))
+	assert.Assert(t, conf.Unsigned.Match(65535))
+	assert.Assert(t, conf.Ratio.Match(0.25))
+	assert.Assert(t, conf.Debug.Match(true))
+	assert.Assert(t, conf.Started.Match(time.Date(2023, 6, 1, 12, 30, 0, 0, time.UTC)))
+	assert.Assert(t, conf.Timeout.Match(90*time.Second))
+	assert.Assert(t, conf.Name.Match("optional"))
+	assert.Assert(t, conf.Password.Match("hunter2"))
+	assert.Assert(t, conf.Generic.Match(7))
+	assert.Assert(t, conf.Label.IsNone())
+	assert.Assert(t, conf.Missing.IsNone())
+	assert.Assert(t, conf.Server.Host.Match("localhost"))
+	assert.Assert(t, conf.Server.Port.IsNone())
+}
+
+func TestTOMLDecodeErrors(t *testing.T) {
+	var conf tomlTestConfig
+	_, err := toml.Decode(`small = 300`, &conf)
+	assert.Assert(t, err != nil)
+
+	_, err = toml.Decode(`unsigned = -1`, &conf)
+	assert.Assert(t, err != nil)
+
+	_, err = toml.Decode(`debug = 1`, &conf)
+	assert.Assert(t, err != nil)
+
+	// Strings are still parsed for numeric types
+	_, err = toml.Decode(`count = "12"`, &conf)
+	assert.NilError(t, err)
+	assert.Assert(t, conf.Count.Match(12))
+}
+
+func TestTOMLEncodeOmitsNone(t *testing.T) {
+	conf := tomlTestConfig{
+		Count:   optional.SomeInt(42),
+		Ratio:   optional.SomeFloat64(2),
+		Started: optional.SomeTime(time.Date(2023, 6, 1, 12, 30, 0, 0, time.UTC)),
+		Timeout: optional.SomeDuration(time.Second),
+		Label:   optional.Some("say \"hi\""),
+		Server:  tomlTestServer{Port: optional.SomeInt(8080)},
+	}
+
+	var buf bytes.Buffer
+	err := optional.EncodeTOML(&buf, conf)
+	assert.NilError(t, err)
+	doc := buf.String()
+
+	assert.Assert(t, strings.Contains(doc, "count = 42"), doc)
+	assert.Assert(t, strings.Contains(doc, "ratio = 2.0"), doc)
+	assert.Assert(t, strings.Contains(doc, "started = 2023-06-01T12:30:00Z"), doc)
+	assert.Assert(t, strings.Contains(doc, `timeout = "1s"`), doc)
+	assert.Assert(t, strings.Contains(doc, `label = "say \"hi\""`), doc)
+	assert.Assert(t, strings.Contains(doc, "port = 8080"), doc)
+	assert.Assert(t, !strings.Contains(doc, "None"), doc)
+	assert.Assert(t, !strings.Contains(doc, "host"), doc)
+
+	// Round trip
+	var out tomlTestConfig
+	_, err = toml.Decode(doc, &out)
+	assert.NilError(t, err)
+	assert.Assert(t, out.Count.Match(42))
+	assert.Assert(t, out.Label.Match("say \"hi\""))
+	assert.Assert(t, out.Server.Port.Match(8080))
+	assert.Assert(t, out.Debug.IsNone())
+	assert.Assert(t, out.Server.Host.IsNone())
+}
+
+func TestTOMLEncodeNone(t *testing.T) {
+	// Without omitempty, encoding directly with the toml package writes None values as "None", which round trip
+	conf := tomlTestConfig{Count: optional.SomeInt(1)}
+
+	var buf bytes.Buffer
+	err := toml.NewEncoder(&buf).Encode(conf)
+	assert.NilError(t, err)
+
+	var out tomlTestConfig
+	_, err = toml.Decode(buf.String(), &out)
+	assert.NilError(t, err)
+	assert.Assert(t, out.Count.Match(1))
+	assert.Assert(t, out.Ratio.IsNone())
+	assert.Assert(t, out.Generic.IsNone())
+}
+
+func TestTOMLEncodeOmitEmpty(t *testing.T) {
+	type config struct {
+		Count   optional.Int             `toml:"count,omitempty"`
+		Zero    optional.Int             `toml:"zero,omitempty"`
+		Name    optional.Str             `toml:"name,omitempty"`
+		Generic optional.Option[float32] `toml:"generic,omitempty"`
+		Tags    optional.Slice[string]   `toml:"tags,omitempty"`
+		Server  *tomlTestServer          `toml:"server,omitempty"`
+	}
+
+	conf := config{Count: optional.SomeInt(1), Zero: optional.SomeInt(0), Name: optional.SomeStr("old")}
+	conf.Name.Clear()
+
+	var buf bytes.Buffer
+	err := toml.NewEncoder(&buf).Encode(conf)
+	assert.NilError(t, err)
+	doc := buf.String()
+
+	// Some(0) is still written, only None is left out
+	assert.Assert(t, strings.Contains(doc, "count = 1"), doc)
+	assert.Assert(t, strings.Contains(doc, "zero = 0"), doc)
+	assert.Assert(t, !strings.Contains(doc, "None"), doc)
+	assert.Assert(t, !strings.Contains(doc, "name"), doc)
+
+	// EncodeTOML handles nil pointers to optionals
+	type pointers struct {
+		Port *optional.Int `toml:"port"`
+		Host *optional.Str `toml:"host"`
+	}
+	host := optional.SomeStr("localhost")
+	buf.Reset()
+	assert.NilError(t, optional.EncodeTOML(&buf, pointers{Host: &host}))
+	assert.Equal(t, "host = \"localhost\"\n", buf.String())
+}
+
+func TestTimeMarshalTOML(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.NilError(t, err)
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	type config struct {
+		Started optional.Time `toml:"started"`
+	}
+	encode := func(o optional.Time) string {
+		var buf bytes.Buffer
+		assert.NilError(t, toml.NewEncoder(&buf).Encode(config{o}))
+		return strings.TrimSpace(buf.String())
+	}
+
+	assert.Equal(t, "started = 2024-01-02T03:04:05Z", encode(optional.SomeTime(when)))
+	assert.Equal(t, "started = 2024-01-02T12:04:05+09:00", encode(optional.SomeTime(when).WithLocation(tokyo)))
+	assert.Equal(t, `started = "None"`, encode(optional.NoTime()))
+
+	custom := optional.SomeTime(when, time.DateTime).WithLocation(tokyo)
+	custom.DataFormat = time.DateTime
+	assert.Equal(t, `started = "2024-01-02 12:04:05"`, encode(custom))
+
+	epoch := optional.SomeTime(when)
+	epoch.DataFormat = optional.EPOCH_MILLISECONDS_FORMAT
+	assert.Equal(t, "started = 1704164645000", encode(epoch))
+
+	// Each form is read back to the same instant
+	for _, o := range []optional.Time{custom, epoch, optional.SomeTime(when).WithLocation(tokyo)} {
+		out := config{optional.NoTime(time.DateTime).WithLocation(tokyo)}
+		out.Started.EpochUnit = time.Millisecond
+		_, err := toml.Decode(encode(o), &out)
+		assert.NilError(t, err)
+		assert.Assert(t, out.Started.MustGet().Equal(when), out.Started.MustGet())
+	}
+}
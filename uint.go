@@ -68,6 +68,35 @@ func (o *Uint) UnmarshalText(text []byte) error {
 	return nil
 }
 
+// Scan implements database/sql.Scanner interface. int64, float64, bool, string, and []byte values are all accepted
+// as long as the value fits into uint.
+func (o *Uint) Scan(src any) error {
+	if src == nil {
+		// NULL value row
+		o.Clear()
+		return nil
+	}
+	val, err := scanNumber[uint](src)
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
+	}
+	_ = o.Replace(val)
+	return nil
+}
+
+// Value implements the database/sql/driver.Valuer interface. Values too large to fit into an int64 are stored as
+// strings, the same as Uint64.
+func (o Uint) Value() (driver.Value, error) {
+	val, ok := o.Get()
+	if ok {
+		if uint64(val) > math.MaxInt64 {
+			return strconv.FormatUint(uint64(val), 10), nil
+		}
+		return int64(val), nil
+	}
+	return nil, nil
+}
+
 func (o Uint) Add(i Uint) Uint {
 	a, ok := o.Get()
 	if !ok {
@@ -81,6 +110,12 @@ func (o Uint) Add(i Uint) Uint {
 	return SomeUint(a + b)
 }
 
+// Cmp compares o with other the same as Compare, with None sorting first. It can be passed straight to slices.SortFunc
+// as Uint.Cmp.
+func (o Uint) Cmp(other Uint) int {
+	return Compare(o.Option, other.Option, true)
+}
+
 // 8bit sized uint
 
 type Uint8 struct {
@@ -142,25 +177,19 @@ func (o *Uint8) UnmarshalText(text []byte) error {
 	return nil
 }
 
-// Scan implements database/sql.Scanner interface.
+// Scan implements database/sql.Scanner interface. int64, float64, bool, string, and []byte values are all accepted
+// as long as the value fits into uint8.
 func (o *Uint8) Scan(src any) error {
 	if src == nil {
 		// NULL value row
 		o.Clear()
 		return nil
 	}
-	switch t := src.(type) {
-	case int64:
-		if t > math.MaxUint8 || t < 0 {
-			return fmt.Errorf("int64 of value %d cannot fit in uint8", t)
-		}
-		_ = o.Replace(uint8(t))
-	case uint8:
-		// This shouldn't ever actually happen
-		_ = o.Replace(t)
-	default:
-		return fmt.Errorf("converting driver.Value type %T to %s", src, o.Type())
+	val, err := scanNumber[uint8](src)
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
 	}
+	_ = o.Replace(val)
 	return nil
 }
 
@@ -186,6 +215,12 @@ func (o Uint8) Add(i Uint8) Uint8 {
 	return SomeUint8(a + b)
 }
 
+// Cmp compares o with other the same as Compare, with None sorting first. It can be passed straight to slices.SortFunc
+// as Uint8.Cmp.
+func (o Uint8) Cmp(other Uint8) int {
+	return Compare(o.Option, other.Option, true)
+}
+
 // 16bit sized uint
 
 type Uint16 struct {
@@ -247,24 +282,19 @@ func (o *Uint16) UnmarshalText(text []byte) error {
 	return nil
 }
 
-// Scan implements database/sql.Scanner interface.
+// Scan implements database/sql.Scanner interface. int64, float64, bool, string, and []byte values are all accepted
+// as long as the value fits into uint16.
 func (o *Uint16) Scan(src any) error {
 	if src == nil {
 		// NULL value row
 		o.Clear()
 		return nil
 	}
-	switch t := src.(type) {
-	case int64:
-		if t > math.MaxUint16 || t < 0 {
-			return fmt.Errorf("int64 of value %d cannot fit in uint16", t)
-		}
-		_ = o.Replace(uint16(t))
-	case uint16:
-		_ = o.Replace(t)
-	default:
-		return fmt.Errorf("converting driver.Value type %T to %s", src, o.Type())
+	val, err := scanNumber[uint16](src)
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
 	}
+	_ = o.Replace(val)
 	return nil
 }
 
@@ -290,6 +320,12 @@ func (o Uint16) Add(i Uint16) Uint16 {
 	return SomeUint16(a + b)
 }
 
+// Cmp compares o with other the same as Compare, with None sorting first. It can be passed straight to slices.SortFunc
+// as Uint16.Cmp.
+func (o Uint16) Cmp(other Uint16) int {
+	return Compare(o.Option, other.Option, true)
+}
+
 // 32bit sized uint
 
 type Uint32 struct {
@@ -351,24 +387,19 @@ func (o *Uint32) UnmarshalText(text []byte) error {
 	return nil
 }
 
-// Scan implements database/sql.Scanner interface.
+// Scan implements database/sql.Scanner interface. int64, float64, bool, string, and []byte values are all accepted
+// as long as the value fits into uint32.
 func (o *Uint32) Scan(src any) error {
 	if src == nil {
 		// NULL value row
 		o.Clear()
 		return nil
 	}
-	switch t := src.(type) {
-	case int64:
-		if t > math.MaxUint32 || t < 0 {
-			return fmt.Errorf("int64 of value %d cannot fit in uint32", t)
-		}
-		_ = o.Replace(uint32(t))
-	case uint32:
-		_ = o.Replace(t)
-	default:
-		return fmt.Errorf("converting driver.Value type %T to %s", src, o.Type())
+	val, err := scanNumber[uint32](src)
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
 	}
+	_ = o.Replace(val)
 	return nil
 }
 
@@ -394,6 +425,12 @@ func (o Uint32) Add(i Uint32) Uint32 {
 	return SomeUint32(a + b)
 }
 
+// Cmp compares o with other the same as Compare, with None sorting first. It can be passed straight to slices.SortFunc
+// as Uint32.Cmp.
+func (o Uint32) Cmp(other Uint32) int {
+	return Compare(o.Option, other.Option, true)
+}
+
 // 64bit sized uint
 
 type Uint64 struct {
@@ -455,31 +492,19 @@ func (o *Uint64) UnmarshalText(text []byte) error {
 	return nil
 }
 
-// Scan implements database/sql.Scanner interface.
+// Scan implements database/sql.Scanner interface. int64, float64, bool, string, and []byte values are all accepted
+// as long as the value fits into uint64.
 func (o *Uint64) Scan(src any) error {
 	if src == nil {
 		// NULL value row
 		o.Clear()
 		return nil
 	}
-	switch t := src.(type) {
-	case int64:
-		if t < 0 {
-			// max(uint64) >> max(int64)
-			return fmt.Errorf("negative value %d cannot convert to uint64", t)
-		}
-		_ = o.Replace(uint64(t))
-	case string:
-		tmp, err := strconv.ParseUint(t, 10, 64)
-		if err != nil {
-			return err
-		}
-		_ = o.Replace(tmp)
-	case uint64:
-		_ = o.Replace(t)
-	default:
-		return fmt.Errorf("converting driver.Value type %T to %s", src, o.Type())
+	val, err := scanNumber[uint64](src)
+	if err != nil {
+		return fmt.Errorf("converting driver.Value type %T to %s: %w", src, o.Type(), err)
 	}
+	_ = o.Replace(val)
 	return nil
 }
 
@@ -504,3 +529,9 @@ func (o Uint64) Add(i Uint64) Uint64 {
 	}
 	return SomeUint64(a + b)
 }
+
+// Cmp compares o with other the same as Compare, with None sorting first. It can be passed straight to slices.SortFunc
+// as Uint64.Cmp.
+func (o Uint64) Cmp(other Uint64) int {
+	return Compare(o.Option, other.Option, true)
+}